@@ -3,29 +3,68 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 
 	"gendo/internal/gendo"
 	"gendo/pkg/log"
+	"gendo/pkg/server"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "walinspect" {
+		runWalInspect(os.Args[2:])
+		return
+	}
+
 	verbose := flag.Bool("verbose", false, "Enable verbose logging")
 	model := flag.String("model", "", "Model to use for LLM (overrides GENDO_MODEL environment variable)")
 	flag.StringVar(model, "m", "", "Model to use for LLM (shorthand)")
+	backend := flag.String("backend", "", "LLM backend to use: \"openai\" or \"grpc\" (overrides GENDO_BACKEND environment variable)")
+	watch := flag.Bool("watch", false, "Keep running and hot-reload the script on changes")
+	addr := flag.String("serve", "", "Expose the script as an HTTP service on this address (e.g. :8080) instead of running it over stdin")
+	walDir := flag.String("wal-dir", "", "Directory for the write-ahead log, enabling resumable execution (disabled if empty)")
+	resume := flag.Bool("resume", false, "Resume from the write-ahead log in -wal-dir instead of starting over from the first input line")
+	maxParallel := flag.Int("max-parallel", 0, "Maximum number of nodes to run concurrently per input line's DAG (0 means unlimited)")
 	flag.Parse()
 
 	args := flag.Args()
 	if len(args) != 1 {
-		fmt.Fprintf(os.Stderr, "Usage: %s [-verbose] [-m model] <script>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [-verbose] [-m model] [-watch] [-serve addr] [-wal-dir dir] [-resume] [-max-parallel N] <script>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s walinspect -wal-dir dir\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	if *resume && *walDir == "" {
+		fmt.Fprintln(os.Stderr, "-resume requires -wal-dir")
 		os.Exit(1)
 	}
 
 	log.SetVerbose(*verbose)
 	log.Debug("Verbose logging enabled")
 
-	if err := gendo.Run(args[0], *model); err != nil {
-		log.Error("Failed to run script: %v", err)
+	if *addr != "" {
+		srv, err := server.New(args[0], *model, *backend)
+		if err != nil {
+			log.Error("Failed to load script: %v", err)
+			os.Exit(1)
+		}
+		log.Debug("Serving %s on %s", args[0], *addr)
+		if err := http.ListenAndServe(*addr, srv); err != nil {
+			log.Error("HTTP server exited: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	runErr := error(nil)
+	if *watch {
+		runErr = gendo.Watch(args[0], *model, *backend)
+	} else {
+		runErr = gendo.RunWithOptions(args[0], *model, *backend, *walDir, *resume, *maxParallel)
+	}
+	if runErr != nil {
+		log.Error("Failed to run script: %v", runErr)
 		os.Exit(1)
 	}
 } 
\ No newline at end of file
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"gendo/pkg/wal"
+)
+
+// runWalInspect implements the "gendo walinspect" subcommand, dumping every
+// record in a write-ahead log directory for debugging a stuck or crashed
+// -resume run. It takes its own flag set rather than sharing main's, since
+// none of the script-running flags (-model, -watch, -serve, ...) apply here.
+func runWalInspect(args []string) {
+	fs := flag.NewFlagSet("walinspect", flag.ExitOnError)
+	walDir := fs.String("wal-dir", "", "Write-ahead log directory to inspect (required)")
+	fs.Parse(args)
+
+	if *walDir == "" {
+		fmt.Fprintln(os.Stderr, "Usage: gendo walinspect -wal-dir dir")
+		os.Exit(1)
+	}
+
+	records, err := wal.Scan(*walDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to scan WAL at %q: %v\n", *walDir, err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, rec := range records {
+		if err := encoder.Encode(rec); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to print WAL record: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	resumeFrom, _ := wal.Replay(records)
+	fmt.Fprintf(os.Stderr, "%d records, next resume would start at input line %d\n", len(records), resumeFrom)
+}
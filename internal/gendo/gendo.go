@@ -2,11 +2,19 @@ package gendo
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
+	"gendo/pkg/config"
 	"gendo/pkg/llm"
+	"gendo/pkg/llm/grpc"
+	"gendo/pkg/llm/ollama"
 	"gendo/pkg/llm/openai"
 	"gendo/pkg/log"
 	"gendo/pkg/parser"
@@ -15,6 +23,7 @@ import (
 	"gendo/pkg/tools/rand"
 	readtool "gendo/pkg/tools/read"
 	writetool "gendo/pkg/tools/write"
+	"gendo/pkg/wal"
 )
 
 // NodeType represents the type of a node
@@ -25,32 +34,59 @@ const (
 	NodeTypeIn      NodeType = "in"
 	NodeTypeOut     NodeType = "out"
 	NodeTypeErr     NodeType = "err"
+	NodeTypeHTTP    NodeType = "http"
 	NodeTypeDefault NodeType = ""
 )
 
 // Node represents a Gendo node with its ID, references, and optional prompt
 type Node struct {
-	ID     int
-	Refs   []int
-	Prompt string
-	Tool   string
-	Type   NodeType
+	ID           int
+	Refs         []int
+	Prompt       string
+	Tool         string
+	HTTPMethod   string
+	HTTPPath     string
+	LLM          string   // Name of the LLM registry entry to route Prompt to, e.g. "falcon" or an "openai:gpt-4o" composite key; empty selects "openai"
+	BackendChain []string // Ordered registry names to retry Prompt against on error, from a "backend: name1,name2 ..." definition; empty disables the fallback chain
+	Stream       bool     // Whether to consume this node's tool/LLM output token-by-token, logging each Token at Debug level
+	Type         NodeType
 }
 
-// processNode processes input through a node, either using OpenAI API, tool, or passthrough
-func processNode(node Node, input string, toolRegistry tools.Registry, llmRegistry llm.Registry) (string, error) {
-	log.Debug("Processing node %d with input: %q", node.ID, input)
+// processNode processes input through a node, either using OpenAI API, tool,
+// or passthrough. A nil logger falls back to log.Default(); callers that
+// process many nodes in one run should pass a logger tagged with a pipeline
+// ID via With so every node's lines can be told apart.
+func processNode(node Node, input string, toolRegistry tools.Registry, llmRegistry llm.Registry, logger *log.Logger) (string, error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger = logger.With("node", node.ID)
+	logger.Debug("Processing node %d with input: %q", node.ID, input)
 
 	switch node.Type {
 	case NodeTypeTool:
 		if tool := toolRegistry.Get(node.Tool); tool != nil {
-			log.Debug("Using tool %q for node %d", node.Tool, node.ID)
+			logger.Debug("Using tool %q for node %d", node.Tool, node.ID)
+			if node.Stream {
+				ch, err := tools.ProcessStream(tool, input)
+				if err != nil {
+					logger.Debug("Tool %q failed: %v", node.Tool, err)
+					return "", fmt.Errorf("tool %q failed: %v", node.Tool, err)
+				}
+				result, err := drainToolTokens(ch, logger)
+				if err != nil {
+					logger.Debug("Tool %q failed: %v", node.Tool, err)
+					return "", fmt.Errorf("tool %q failed: %v", node.Tool, err)
+				}
+				logger.Debug("Tool %q returned (streamed): %q", node.Tool, result)
+				return result, nil
+			}
 			result, err := tool.Process(input)
 			if err != nil {
-				log.Debug("Tool %q failed: %v", node.Tool, err)
+				logger.Debug("Tool %q failed: %v", node.Tool, err)
 				return "", fmt.Errorf("tool %q failed: %v", node.Tool, err)
 			}
-			log.Debug("Tool %q returned: %q", node.Tool, result)
+			logger.Debug("Tool %q returned: %q", node.Tool, result)
 			return result, nil
 		}
 		return "", fmt.Errorf("unknown tool: %s", node.Tool)
@@ -63,29 +99,121 @@ func processNode(node Node, input string, toolRegistry tools.Registry, llmRegist
 	case NodeTypeErr:
 		// Error nodes are handled separately in processInput
 		return input, nil
+	case NodeTypeHTTP:
+		// HTTP-bound nodes are addressed directly by pkg/server; treat them
+		// as passthrough when reached through the normal line pipeline.
+		return input, nil
 	default:
 		if node.Prompt != "" {
-			// Use the OpenAI LLM for processing
-			if llm := llmRegistry.Get("openai"); llm != nil {
-				log.Debug("Using OpenAI LLM for node %d with prompt: %q", node.ID, node.Prompt)
-				result, err := llm.Process(node.Prompt, input)
+			if len(node.BackendChain) > 0 {
+				return processBackendChain(node, input, llmRegistry, logger)
+			}
+
+			llmName := node.LLM
+			if llmName == "" {
+				llmName = "openai"
+			}
+			if backendLLM := llmRegistry.Get(llmName); backendLLM != nil {
+				logger.Debug("Using LLM %q for node %d with prompt: %q", llmName, node.ID, node.Prompt)
+				if node.Stream {
+					ch, err := llm.ProcessStream(backendLLM, node.Prompt, input)
+					if err != nil {
+						logger.Debug("LLM %q failed: %v", llmName, err)
+						return "", err
+					}
+					result, err := drainLLMTokens(ch, logger)
+					if err != nil {
+						logger.Debug("LLM %q failed: %v", llmName, err)
+						return "", err
+					}
+					logger.Debug("LLM %q returned (streamed): %q", llmName, result)
+					return result, nil
+				}
+				result, err := backendLLM.Process(node.Prompt, input)
 				if err != nil {
-					log.Debug("OpenAI LLM failed: %v", err)
+					logger.Debug("LLM %q failed: %v", llmName, err)
 					return "", err
 				}
-				log.Debug("OpenAI LLM returned: %q", result)
+				logger.Debug("LLM %q returned: %q", llmName, result)
 				return result, nil
 			}
-			return "", fmt.Errorf("no LLM available")
+			return "", fmt.Errorf("unknown LLM backend: %s", llmName)
 		}
-		log.Debug("Node %d is a passthrough node", node.ID)
+		logger.Debug("Node %d is a passthrough node", node.ID)
 		return input, nil // Passthrough for non-AI nodes
 	}
 }
 
-// processInput processes a single input line according to Gendo rules
-func processInput(line string, nodes map[int]Node, toolRegistry tools.Registry, llmRegistry llm.Registry, stdoutDefault, stderrDefault int, stdout, stderr io.Writer) error {
-	log.Debug("Processing input line: %q", line)
+// processBackendChain sends node.Prompt to each registry entry in
+// node.BackendChain in order, returning the first one that succeeds. Each
+// failure is logged and the next entry is tried; if every entry fails, the
+// last entry's error is returned so processInput's normal error routing (to
+// the err node) still applies.
+func processBackendChain(node Node, input string, llmRegistry llm.Registry, logger *log.Logger) (string, error) {
+	var lastErr error
+	for _, name := range node.BackendChain {
+		backendLLM := llmRegistry.Get(name)
+		if backendLLM == nil {
+			lastErr = fmt.Errorf("unknown LLM backend: %s", name)
+			logger.Debug("Backend chain: %v, trying next", lastErr)
+			continue
+		}
+
+		result, err := backendLLM.Process(node.Prompt, input)
+		if err != nil {
+			lastErr = err
+			logger.Debug("Backend chain: %q failed: %v, trying next", name, err)
+			continue
+		}
+
+		logger.Debug("Backend chain: %q returned: %q", name, result)
+		return result, nil
+	}
+	return "", lastErr
+}
+
+// drainToolTokens consumes a tools.Token channel to completion, logging each
+// token at Debug level and concatenating its Text into the final result, so a
+// "stream " node can exercise a tool's StreamingTool path while still
+// returning the single string the rest of the synchronous node chain expects.
+// A Token carrying a non-nil Err is returned as the function's error.
+func drainToolTokens(ch <-chan tools.Token, logger *log.Logger) (string, error) {
+	var result strings.Builder
+	for token := range ch {
+		if token.Err != nil {
+			return "", token.Err
+		}
+		logger.Debug("Received stream token: %q", token.Text)
+		result.WriteString(token.Text)
+	}
+	return result.String(), nil
+}
+
+// drainLLMTokens is drainToolTokens's counterpart for an llm.Token channel,
+// see its doc comment.
+func drainLLMTokens(ch <-chan llm.Token, logger *log.Logger) (string, error) {
+	var result strings.Builder
+	for token := range ch {
+		if token.Err != nil {
+			return "", token.Err
+		}
+		logger.Debug("Received stream token: %q", token.Text)
+		result.WriteString(token.Text)
+	}
+	return result.String(), nil
+}
+
+// processInput processes a single input line according to Gendo rules. A nil
+// logger falls back to log.Default(). walMgr, if non-nil, records every in,
+// out, and chain node invocation for inputLineID to the write-ahead log (see
+// RunWithOptions); the err node's invocation is not recorded.
+// resumeCompleted, when resuming a crashed run, supplies already-recorded
+// output for nodes that ran before the crash so they aren't re-invoked.
+func processInput(line string, nodes map[int]Node, toolRegistry tools.Registry, llmRegistry llm.Registry, stdoutDefault, stderrDefault int, stdout, stderr io.Writer, logger *log.Logger, walMgr *wal.Manager, inputLineID int, resumeCompleted map[int]wal.CompletedNode, maxParallel int) error {
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Debug("Processing input line: %q", line)
 
 	// Set up default I/O if not provided
 	if stdout == nil {
@@ -110,10 +238,10 @@ func processInput(line string, nodes map[int]Node, toolRegistry tools.Registry,
 
 	// Process through input node if defined
 	if inNode != nil {
-		log.Debug("Processing through input node %d", inNode.ID)
-		output, err := processNode(*inNode, line, toolRegistry, llmRegistry)
+		logger.Debug("Processing through input node %d", inNode.ID)
+		output, err := processNodeWithWAL(*inNode, line, toolRegistry, llmRegistry, walMgr, inputLineID, resumeCompleted, logger)
 		if err != nil {
-			log.Error("Input node failed: %v", err)
+			logger.Error("Input node failed: %v", err)
 			fmt.Fprintf(stderr, "Error: %v\n", err)
 			return err
 		}
@@ -122,141 +250,654 @@ func processInput(line string, nodes map[int]Node, toolRegistry tools.Registry,
 
 	// Process through output node if defined
 	if outNode != nil {
-		log.Debug("Processing through output node %d", outNode.ID)
-		output, err := processNode(*outNode, line, toolRegistry, llmRegistry)
+		logger.Debug("Processing through output node %d", outNode.ID)
+		output, err := processNodeWithWAL(*outNode, line, toolRegistry, llmRegistry, walMgr, inputLineID, resumeCompleted, logger)
 		if err != nil {
-			log.Error("Output node failed: %v", err)
+			logger.Error("Output node failed: %v", err)
 			fmt.Fprintf(stderr, "Error: %v\n", err)
 			return err
 		}
 		line = output
 	}
 
-	// Process through the chain of nodes defined in the script
-	for nodeID := 3; nodeID >= 1; nodeID-- {
-		if node, ok := nodes[nodeID]; ok {
-			log.Debug("Processing through node %d", nodeID)
-			output, err := processNode(node, line, toolRegistry, llmRegistry)
-			if err != nil {
-				if errNode != nil {
-					log.Debug("Processing error through error node %d", errNode.ID)
-					errOutput, _ := processNode(*errNode, err.Error(), toolRegistry, llmRegistry)
-					fmt.Fprintln(stderr, errOutput)
-				} else if stderrDefault > 0 {
-					if errNode, ok := nodes[stderrDefault]; ok {
-						log.Debug("Processing error through default error node %d", stderrDefault)
-						errOutput, _ := processNode(errNode, err.Error(), toolRegistry, llmRegistry)
-						fmt.Fprintln(stderr, errOutput)
-					}
-				}
-				return err
+	// Process through the DAG of nodes defined in the script, following
+	// each node's Refs (see executeDAG).
+	output, err := executeDAG(chainNodes(nodes), line, toolRegistry, llmRegistry, walMgr, inputLineID, resumeCompleted, maxParallel, logger)
+	if err != nil {
+		if errNode != nil {
+			logger.Debug("Processing error through error node %d", errNode.ID)
+			errOutput, _ := processNode(*errNode, err.Error(), toolRegistry, llmRegistry, logger)
+			fmt.Fprintln(stderr, errOutput)
+		} else if stderrDefault > 0 {
+			if errNode, ok := nodes[stderrDefault]; ok {
+				logger.Debug("Processing error through default error node %d", stderrDefault)
+				errOutput, _ := processNode(errNode, err.Error(), toolRegistry, llmRegistry, logger)
+				fmt.Fprintln(stderr, errOutput)
 			}
-			line = output
 		}
+		return err
 	}
+	line = output
 
-	log.Debug("Final output: %q", line)
+	logger.Debug("Final output: %q", line)
 	fmt.Fprintln(stdout, line)
+
+	if walMgr != nil {
+		if err := walMgr.Checkpoint(inputLineID); err != nil {
+			logger.Error("Failed to checkpoint WAL for input line %d: %v", inputLineID, err)
+		}
+	}
+
 	return nil
 }
 
-// Run executes a Gendo script from a file
-func Run(filename string, model string) error {
-	log.Debug("Running script: %s", filename)
+// processNodeWithWAL wraps processNode with write-ahead logging: if node.ID
+// has an already-recorded result for inputLineID in resumeCompleted (from a
+// prior, crashed attempt at this same line), that result is returned without
+// re-invoking the node; otherwise processNode runs as usual, bracketed by a
+// wal.PhaseStart record and a wal.PhaseDone/PhaseError record. A nil walMgr
+// disables logging entirely, matching every other optional-logger path in
+// this package.
+func processNodeWithWAL(node Node, input string, toolRegistry tools.Registry, llmRegistry llm.Registry, walMgr *wal.Manager, inputLineID int, resumeCompleted map[int]wal.CompletedNode, logger *log.Logger) (string, error) {
+	if cn, ok := resumeCompleted[node.ID]; ok {
+		logger.Debug("Reusing WAL-recorded result for node %d on resumed input line %d", node.ID, inputLineID)
+		if cn.Err != "" {
+			return "", errors.New(cn.Err)
+		}
+		return cn.Output, nil
+	}
+
+	if walMgr != nil {
+		if err := walMgr.Append(wal.Record{InputLineID: inputLineID, NodeID: node.ID, Phase: wal.PhaseStart, Input: input}); err != nil {
+			logger.Error("Failed to write WAL start record for node %d: %v", node.ID, err)
+		}
+	}
+
+	output, err := processNode(node, input, toolRegistry, llmRegistry, logger)
+
+	if walMgr != nil {
+		rec := wal.Record{InputLineID: inputLineID, NodeID: node.ID, Phase: wal.PhaseDone, Output: output}
+		if err != nil {
+			rec.Phase = wal.PhaseError
+			rec.Err = err.Error()
+		}
+		if walErr := walMgr.Append(rec); walErr != nil {
+			logger.Error("Failed to write WAL result record for node %d: %v", node.ID, walErr)
+		}
+	}
+
+	return output, err
+}
+
+// chainNodes returns the subset of nodes that executeDAG should route line
+// through: everything except the in/out/err nodes, which processInput
+// already handles on either side of the chain.
+func chainNodes(nodes map[int]Node) map[int]Node {
+	chain := make(map[int]Node, len(nodes))
+	for id, node := range nodes {
+		switch node.Type {
+		case NodeTypeIn, NodeTypeOut, NodeTypeErr:
+			continue
+		}
+		chain[id] = node
+	}
+	return chain
+}
+
+// executeDAG runs every node in chain, following the dependency graph each
+// node's Refs induces: "1 : 2 3 4" makes node 1's output the input to nodes
+// 2, 3, and 4 (fan-out). A node named as a Refs target by more than one
+// other node (fan-in) receives those outputs newline-joined, in
+// predecessor-ID order, as its own input. Nodes with no predecessor (roots)
+// receive line directly; nodes with no successor (sinks) contribute to the
+// final result, newline-joined in node-ID order, the same way. A chain
+// with no Refs at all (every node a root and a sink) therefore runs every
+// node concurrently against line and joins all their outputs. Refs come
+// only from a pure reference definition such as "3 : 2", which makes node 3
+// a passthrough feeding node 2; a tool, llm, or "N : : prompt" node has no
+// Refs, so it is always a sink.
+// maxParallel bounds how many nodes run at once; 0 or negative means
+// unlimited. A failed node's error is passed on to every node depending on
+// it, directly or transitively, which is skipped rather than run; nodes
+// that don't depend on it still run. If any sink failed or was skipped, the
+// line fails with the error of the lowest-numbered such sink.
+func executeDAG(chain map[int]Node, line string, toolRegistry tools.Registry, llmRegistry llm.Registry, walMgr *wal.Manager, inputLineID int, resumeCompleted map[int]wal.CompletedNode, maxParallel int, logger *log.Logger) (string, error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	if len(chain) == 0 {
+		return line, nil
+	}
+
+	predecessors := make(map[int][]int, len(chain))
+	indegree := make(map[int]int, len(chain))
+	for id := range chain {
+		indegree[id] = 0
+	}
+	for id, node := range chain {
+		for _, ref := range node.Refs {
+			if _, ok := chain[ref]; !ok {
+				continue
+			}
+			predecessors[ref] = append(predecessors[ref], id)
+			indegree[ref]++
+		}
+	}
+
+	if cycle := findCycle(chain, indegree); cycle != "" {
+		return "", fmt.Errorf("cycle detected in node routing: %s", cycle)
+	}
+
+	if maxParallel <= 0 {
+		maxParallel = len(chain)
+	}
+	sem := make(chan struct{}, maxParallel)
+
+	done := make(map[int]chan struct{}, len(chain))
+	for id := range chain {
+		done[id] = make(chan struct{})
+	}
+
+	var mu sync.Mutex
+	outputs := make(map[int]string, len(chain))
+	errs := make(map[int]error, len(chain))
+
+	var wg sync.WaitGroup
+	for id, node := range chain {
+		wg.Add(1)
+		go func(id int, node Node) {
+			defer wg.Done()
+			defer close(done[id])
+
+			preds := predecessors[id]
+			for _, predID := range preds {
+				<-done[predID]
+			}
+
+			sort.Ints(preds)
+			mu.Lock()
+			var failed error
+			parts := make([]string, len(preds))
+			for i, predID := range preds {
+				if err := errs[predID]; err != nil && failed == nil {
+					failed = err
+				}
+				parts[i] = outputs[predID]
+			}
+			mu.Unlock()
+
+			if failed != nil {
+				mu.Lock()
+				errs[id] = failed
+				mu.Unlock()
+				return
+			}
+
+			input := line
+			if len(preds) > 0 {
+				input = strings.Join(parts, "\n")
+			}
+
+			logger.Debug("Processing through node %d", id)
+			sem <- struct{}{}
+			output, err := processNodeWithWAL(node, input, toolRegistry, llmRegistry, walMgr, inputLineID, resumeCompleted, logger)
+			<-sem
+
+			mu.Lock()
+			outputs[id] = output
+			errs[id] = err
+			mu.Unlock()
+		}(id, node)
+	}
+	wg.Wait()
+
+	var sinkIDs []int
+	for id, node := range chain {
+		hasSuccessor := false
+		for _, ref := range node.Refs {
+			if _, ok := chain[ref]; ok {
+				hasSuccessor = true
+				break
+			}
+		}
+		if !hasSuccessor {
+			sinkIDs = append(sinkIDs, id)
+		}
+	}
+	sort.Ints(sinkIDs)
+
+	for _, id := range sinkIDs {
+		if err := errs[id]; err != nil {
+			return "", err
+		}
+	}
+
+	parts := make([]string, len(sinkIDs))
+	for i, id := range sinkIDs {
+		parts[i] = outputs[id]
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// findCycle reports the node IDs involved in a routing cycle, or "" if
+// chain's Refs form a DAG, via Kahn's algorithm: repeatedly remove
+// zero-indegree nodes until none remain, and whatever is left over only
+// exists because of a cycle.
+func findCycle(chain map[int]Node, indegree map[int]int) string {
+	remaining := make(map[int]int, len(indegree))
+	for id, d := range indegree {
+		remaining[id] = d
+	}
+
+	successors := make(map[int][]int, len(chain))
+	for id, node := range chain {
+		for _, ref := range node.Refs {
+			if _, ok := chain[ref]; ok {
+				successors[id] = append(successors[id], ref)
+			}
+		}
+	}
 
+	var queue []int
+	for id, d := range remaining {
+		if d == 0 {
+			queue = append(queue, id)
+		}
+	}
+
+	visited := 0
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, next := range successors[id] {
+			remaining[next]--
+			if remaining[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if visited == len(chain) {
+		return ""
+	}
+
+	var stuck []string
+	for id, d := range remaining {
+		if d > 0 {
+			stuck = append(stuck, strconv.Itoa(id))
+		}
+	}
+	sort.Strings(stuck)
+	return strings.Join(stuck, ", ")
+}
+
+// readScriptLines reads filename into a slice of lines, as Run does before
+// parsing it.
+func readScriptLines(filename string) ([]string, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		log.Error("Failed to open script: %v", err)
-		return fmt.Errorf("failed to open script: %v", err)
+		return nil, fmt.Errorf("failed to open script: %v", err)
 	}
 	defer file.Close()
 
 	var lines []string
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := scanner.Text()
-		log.Debug("Read line: %q", line)
-		lines = append(lines, line)
+		lines = append(lines, scanner.Text())
 	}
-
 	if err := scanner.Err(); err != nil {
-		log.Error("Failed to read script: %v", err)
-		return fmt.Errorf("failed to read script: %v", err)
+		return nil, fmt.Errorf("failed to read script: %v", err)
 	}
+	return lines, nil
+}
 
-	log.Debug("Read %d lines from script", len(lines))
-
-	// Parse script using the new parser
-	nodes := make(map[int]Node)
-	var inputLines []string
-	defaultErrorNode := 0
+// parseScript parses the lines of a Gendo script into its node table,
+// literal script input lines, and default error node, as Run and the
+// --watch reloader both need. A nil logger falls back to log.Default().
+func parseScript(lines []string, logger *log.Logger) (nodes map[int]Node, inputLines []string, defaultErrorNode int) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	nodes = make(map[int]Node)
 
 	for _, line := range lines {
-		result, ok := parser.ParseLine(line)
+		result, ok := parser.ParseLine(line, logger)
 		if !ok {
-			log.Debug("Failed to parse line: %q", line)
 			continue
 		}
 
 		switch r := result.(type) {
 		case *parser.NodeDefinition:
-			log.Debug("Parsed node definition: ID=%d, Tool=%q", r.ID, r.Tool)
+			logger.Debug("Parsed node definition: ID=%d, Tool=%q", r.ID, r.Tool)
 			nodes[r.ID] = Node{
-				ID:     r.ID,
-				Refs:   r.RefIDs,
-				Prompt: r.Prompt,
-				Tool:   r.Tool,
-				Type:   NodeType(r.Type),
+				ID:           r.ID,
+				Refs:         r.RefIDs,
+				Prompt:       r.Prompt,
+				Tool:         r.Tool,
+				HTTPMethod:   r.HTTPMethod,
+				HTTPPath:     r.HTTPPath,
+				LLM:          r.LLM,
+				BackendChain: r.BackendChain,
+				Stream:       r.Stream,
+				Type:         NodeType(r.Type),
 			}
 		case *parser.RouteDefinition:
 			if r.Source == 0 && r.Dest == 0 && r.ErrorDest == 0 {
 				// This is an input line
-				log.Debug("Parsed input line: %q", r.Input)
+				logger.Debug("Parsed input line: %q", r.Input)
 				inputLines = append(inputLines, r.Input)
 			} else if r.ErrorDest > 0 {
-				log.Debug("Setting default error node to %d", r.ErrorDest)
+				logger.Debug("Setting default error node to %d", r.ErrorDest)
 				defaultErrorNode = r.ErrorDest
 			}
 		}
 	}
 
-	// Initialize tool registry
-	log.Debug("Initializing tool registry")
-	toolRegistry := tools.NewRegistry()
-	toolRegistry.Register("read", readtool.NewReadTool(""))
-	toolRegistry.Register("write", writetool.NewWriteTool(""))
-	toolRegistry.Register("math", math.NewTool())
-	toolRegistry.Register("rand", rand.New())
+	return nodes, inputLines, defaultErrorNode
+}
 
-	// Initialize LLM registry
-	log.Debug("Initializing LLM registry")
-	llmRegistry := llm.NewRegistry()
-	llmRegistry.Register("openai", openai.New("", "bitnet"))
+// newPrimaryLLM selects the "openai" or "grpc" backend for the primary
+// node LLM according to cfg.Backend (already resolved from CLI flags,
+// environment, config file, and defaults by NewRegistries). The gRPC
+// backend dials cfg.GRPCAddr; if dialing fails, Gendo falls back to the
+// OpenAI backend so a script can still run with no local model server up.
+func newPrimaryLLM(cfg *config.Config, toolRegistry tools.Registry, logger *log.Logger) llm.LLM {
+	if cfg.Backend == "grpc" {
+		grpcLLM, err := grpc.New(cfg.GRPCAddr)
+		if err == nil {
+			logger.Debug("Using gRPC LLM backend at %s", cfg.GRPCAddr)
+			return grpcLLM
+		}
+		logger.Error("Failed to connect to gRPC backend at %s, falling back to openai: %v", cfg.GRPCAddr, err)
+	}
+
+	return openai.NewFromConfig(cfg, toolRegistry)
+}
+
+// registerBackends dials every additional gRPC backend listed in
+// cfg.Backends (see config.Config.Backends for the "name@target" format)
+// and registers it into llmRegistry under its name, so a script's
+// "llm <name> ..." nodes can route to it alongside the primary "openai"
+// entry. A backend that fails to dial is logged and skipped rather than
+// failing the whole registry build, since other backends and the primary
+// LLM may still be usable.
+func registerBackends(cfg *config.Config, llmRegistry llm.Registry, logger *log.Logger) {
+	for _, spec := range cfg.Backends {
+		name, target, ok := strings.Cut(spec, "@")
+		if !ok || name == "" || target == "" {
+			logger.Error("Ignoring malformed backend manifest entry %q: want \"name@target\"", spec)
+			continue
+		}
+
+		backendLLM, err := grpc.New(target)
+		if err != nil {
+			logger.Error("Failed to dial backend %q at %q, skipping: %v", name, target, err)
+			continue
+		}
+
+		logger.Debug("Registered gRPC backend %q at %q", name, target)
+		llmRegistry.Register(name, backendLLM)
+	}
+}
+
+// Run executes a Gendo script from a file
+func Run(filename string, model string) error {
+	return RunWithBackend(filename, model, "")
+}
+
+// RunWithBackend executes a Gendo script from a file, using backend
+// ("openai" or "grpc") to select the primary LLM implementation. An empty
+// backend defers to the configured backend (GENDO_BACKEND, a config file, or
+// config.Load's "openai" default), see loadConfigOverride. Every
+// line logged while running filename is tagged with it as the "pipeline"
+// field, so concurrent Gendo processes (or, eventually, concurrent pipelines
+// in one process) can be told apart in shared log output. It is a thin
+// wrapper over RunWithOptions with the write-ahead log disabled, kept for
+// callers and docs that predate it.
+func RunWithBackend(filename string, model string, backend string) error {
+	return RunWithOptions(filename, model, backend, "", false, 0)
+}
+
+// RunWithOptions is RunWithBackend's fully-parameterized form. walDir, if
+// non-empty, durably records every node invocation under it (see pkg/wal)
+// before moving on to the next node, so a crash or Ctrl-C loses at most the
+// node in flight rather than the whole input line. resume, when walDir is
+// also set, replays walDir first and skips every input line already
+// checkpointed as committed to stdout, reusing the recorded output of any
+// node that completed on the first, incomplete line instead of re-running
+// it (important when that node is an LLM call). maxParallel bounds how many
+// nodes of a single input line's DAG run at once (see executeDAG); 0 or
+// negative means unlimited. cmd/gendo's --wal-dir, --resume, and
+// --max-parallel flags are the intended callers.
+func RunWithOptions(filename string, model string, backend string, walDir string, resume bool, maxParallel int) error {
+	logger := log.Default().With("pipeline", filename)
+	logger.Debug("Running script: %s", filename)
+
+	lines, err := readScriptLines(filename)
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	logger.Debug("Read %d lines from script", len(lines))
+
+	nodes, inputLines, defaultErrorNode := parseScript(lines, logger)
+
+	toolRegistry, llmRegistry := NewRegistries(model, backend, nodes, logger)
+
+	var walMgr *wal.Manager
+	resumeFrom := 0
+	var resumeCompleted map[int]wal.CompletedNode
+
+	if walDir != "" {
+		if resume {
+			records, err := wal.Scan(walDir)
+			if err != nil {
+				logger.Error("Failed to scan WAL at %q: %v", walDir, err)
+				return err
+			}
+			resumeFrom, resumeCompleted = wal.Replay(records)
+			logger.Debug("Resuming from input line %d (%d WAL records replayed)", resumeFrom, len(records))
+		}
+
+		walMgr, err = wal.Open(walDir, logger)
+		if err != nil {
+			logger.Error("Failed to open WAL at %q: %v", walDir, err)
+			return err
+		}
+		defer walMgr.Close()
+	}
+
+	lastCheckpoint := resumeFrom - 1
 
 	// Check if we have input from stdin
 	stat, _ := os.Stdin.Stat()
 	if (stat.Mode() & os.ModeCharDevice) == 0 {
-		log.Debug("Reading input from pipe")
+		logger.Debug("Reading input from pipe")
 		// Input from pipe
 		scanner := bufio.NewScanner(os.Stdin)
+		lineID := 0
 		for scanner.Scan() {
-			if err := processInput(scanner.Text(), nodes, toolRegistry, llmRegistry, 1, defaultErrorNode, os.Stdout, os.Stderr); err != nil {
+			if lineID < resumeFrom {
+				lineID++
+				continue
+			}
+			if err := processInput(scanner.Text(), nodes, toolRegistry, llmRegistry, 1, defaultErrorNode, os.Stdout, os.Stderr, logger, walMgr, lineID, completedFor(lineID, resumeFrom, resumeCompleted), maxParallel); err != nil {
 				return err
 			}
+			lastCheckpoint = lineID
+			lineID++
 		}
 		if err := scanner.Err(); err != nil {
-			log.Error("Failed to read stdin: %v", err)
+			logger.Error("Failed to read stdin: %v", err)
 			return fmt.Errorf("failed to read stdin: %v", err)
 		}
 	} else {
-		log.Debug("Processing %d script input lines", len(inputLines))
+		logger.Debug("Processing %d script input lines", len(inputLines))
 		// Process script input lines
-		for _, line := range inputLines {
-			if err := processInput(line, nodes, toolRegistry, llmRegistry, 1, defaultErrorNode, os.Stdout, os.Stderr); err != nil {
+		for lineID, line := range inputLines {
+			if lineID < resumeFrom {
+				continue
+			}
+			if err := processInput(line, nodes, toolRegistry, llmRegistry, 1, defaultErrorNode, os.Stdout, os.Stderr, logger, walMgr, lineID, completedFor(lineID, resumeFrom, resumeCompleted), maxParallel); err != nil {
 				return err
 			}
+			lastCheckpoint = lineID
 		}
 	}
 
+	if walMgr != nil {
+		if err := walMgr.Compact(lastCheckpoint); err != nil {
+			logger.Error("WAL compaction failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// completedFor returns resumeCompleted for lineID if lineID is the one line
+// Replay found already in flight when the run was interrupted, or nil for
+// every other line, which never has a recorded partial result to reuse.
+func completedFor(lineID, resumeFrom int, resumeCompleted map[int]wal.CompletedNode) map[int]wal.CompletedNode {
+	if lineID == resumeFrom {
+		return resumeCompleted
+	}
 	return nil
 }
+
+// NewRegistries builds the default tool and LLM registries Run/Watch use,
+// for callers (such as pkg/server) that need to process nodes directly
+// without going through Run/Watch's per-line pipeline. model and backend, if
+// non-empty, override the corresponding config.Config fields, preserving the
+// documented CLI-flags-win-over-everything-else precedence on top of
+// config.Load's environment/config-file/defaults merge. nodes is scanned for
+// any node.LLM/node.BackendChain entry naming a "provider" or
+// "provider:model" pair not already covered by the "openai"/"ollama"
+// defaults below, see registerNodeBackends. A nil logger falls back to
+// log.Default(); it is passed to every tool constructor so their debug
+// output can be tied back to this run. The LLM clients themselves log
+// through the package-level log functions.
+func NewRegistries(model, backend string, nodes map[int]Node, logger *log.Logger) (tools.Registry, llm.Registry) {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	cfg := loadConfigOverride(model, backend, logger)
+
+	logger.Debug("Initializing tool registry")
+	toolRegistry := tools.NewRegistry()
+	toolRegistry.Register("read", readtool.NewReadTool(toolBasePath(cfg, "read"), logger))
+	toolRegistry.Register("write", writetool.NewWriteTool(toolBasePath(cfg, "write"), logger))
+	toolRegistry.Register("math", math.NewTool(logger))
+	toolRegistry.Register("rand", rand.New(logger))
+
+	logger.Debug("Initializing LLM registry")
+	llmRegistry := llm.NewRegistry()
+	llmRegistry.Register("openai", newPrimaryLLM(cfg, toolRegistry, logger))
+	llmRegistry.Register("ollama", ollama.NewFromConfig(cfg))
+	registerBackends(cfg, llmRegistry, logger)
+	registerNodeBackends(nodes, cfg, toolRegistry, llmRegistry, logger)
+
+	return toolRegistry, llmRegistry
+}
+
+// loadConfigOverride loads gendo's config, applying model and backend as
+// overrides when non-empty, same precedence as NewRegistries: CLI flags win
+// over the environment/config-file/defaults config.Load already merged. It
+// falls back to a minimal default config if config.Load itself fails.
+func loadConfigOverride(model, backend string, logger *log.Logger) *config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("Failed to load gendo config, using defaults: %v", err)
+		cfg = &config.Config{Backend: "openai", Model: "bitnet"}
+	}
+	if model != "" {
+		cfg.Model = model
+	}
+	if backend != "" {
+		cfg.Backend = backend
+	}
+	return cfg
+}
+
+// registerNodeBackends scans nodes for any "llm"/backend-chain binding that
+// names a registry entry not already registered by NewRegistries (the
+// "openai"/"ollama" defaults and cfg.Backends' gRPC manifest), and registers
+// an ad-hoc client for it so a script can route a single node to e.g.
+// "openai:gpt-4o" without that exact model needing its own config.Backends
+// entry. A binding whose provider (the part before ":", or the whole name
+// if there is no ":") isn't one newProviderLLM recognizes is logged and
+// skipped, consistent with registerBackends' tolerance of partial failure.
+func registerNodeBackends(nodes map[int]Node, cfg *config.Config, toolRegistry tools.Registry, llmRegistry llm.Registry, logger *log.Logger) {
+	for _, node := range nodes {
+		names := append([]string{}, node.BackendChain...)
+		if node.LLM != "" {
+			names = append(names, node.LLM)
+		}
+		for _, name := range names {
+			if llmRegistry.Get(name) != nil {
+				continue
+			}
+
+			provider, model, _ := strings.Cut(name, ":")
+			backendLLM, err := newProviderLLM(provider, model, cfg, toolRegistry)
+			if err != nil {
+				logger.Error("Node %d: failed to register LLM backend %q, skipping: %v", node.ID, name, err)
+				continue
+			}
+
+			logger.Debug("Registered ad-hoc LLM backend %q for node %d", name, node.ID)
+			llmRegistry.Register(name, backendLLM)
+		}
+	}
+}
+
+// newProviderLLM constructs an LLM client for provider ("openai", "ollama",
+// or "grpc"), overriding cfg's configured model with model if non-empty
+// (the grpc client has no model setting, so model has no effect there).
+// cfg's other settings (API key, base URL, gRPC address) are reused
+// unchanged, since a node-level backend binding only ever pins the
+// provider/model, not the whole connection.
+func newProviderLLM(provider, model string, cfg *config.Config, toolRegistry tools.Registry) (llm.LLM, error) {
+	providerCfg := *cfg
+	if model != "" {
+		providerCfg.Model = model
+	}
+
+	switch provider {
+	case "openai":
+		return openai.NewFromConfig(&providerCfg, toolRegistry), nil
+	case "ollama":
+		return ollama.NewFromConfig(&providerCfg), nil
+	case "grpc":
+		return grpc.New(providerCfg.GRPCAddr)
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s", provider)
+	}
+}
+
+// toolBasePath returns the configured base path for the named tool's
+// [tools.<name>] config entry, or "" if the tool has no entry.
+func toolBasePath(cfg *config.Config, name string) string {
+	return cfg.Tools[name].BasePath
+}
+
+// LoadScript parses a Gendo script file into its node table and default
+// error node, for callers that need the graph without running it via
+// Run/Watch.
+func LoadScript(filename string) (nodes map[int]Node, defaultErrorNode int, err error) {
+	lines, err := readScriptLines(filename)
+	if err != nil {
+		return nil, 0, err
+	}
+	nodes, _, defaultErrorNode = parseScript(lines, nil)
+	return nodes, defaultErrorNode, nil
+}
+
+// ProcessNode exposes processNode to callers outside the package, such as
+// pkg/server, that drive individual nodes directly rather than through
+// Run/Watch's per-line pipeline. A nil logger falls back to log.Default().
+func ProcessNode(node Node, input string, toolRegistry tools.Registry, llmRegistry llm.Registry, logger *log.Logger) (string, error) {
+	return processNode(node, input, toolRegistry, llmRegistry, logger)
+}
@@ -1,6 +1,7 @@
 package gendo
 
 import (
+	"fmt"
 	"testing"
 
 	"gendo/pkg/llm"
@@ -20,6 +21,23 @@ func (m *mockLLM) Process(prompt, input string) (string, error) {
 	return m.response, nil
 }
 
+// suffixTool is a tools.Tool that appends a fixed suffix to its input, used
+// to trace a node's position in a DAG through executeDAG's tests.
+type suffixTool struct {
+	suffix string
+}
+
+func (s *suffixTool) Process(input string) (string, error) {
+	return input + s.suffix, nil
+}
+
+// failingTool always errors, used to exercise executeDAG's error handling.
+type failingTool struct{}
+
+func (failingTool) Process(input string) (string, error) {
+	return "", fmt.Errorf("boom")
+}
+
 func TestProcessNode(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -71,7 +89,7 @@ func TestProcessNode(t *testing.T) {
 			toolRegistry := tools.NewRegistry()
 
 			// Process the node
-			_, err := processNode(tt.node, tt.input, toolRegistry, llmRegistry)
+			_, err := processNode(tt.node, tt.input, toolRegistry, llmRegistry, nil)
 			if err != nil {
 				t.Errorf("processNode() error = %v", err)
 				return
@@ -88,4 +106,60 @@ func TestProcessNode(t *testing.T) {
 			}
 		})
 	}
+}
+
+func TestExecuteDAGFanOutFanIn(t *testing.T) {
+	// 1 fans out to 2 and 3; 2 and 3 fan in to 4, the chain's only sink.
+	toolRegistry := tools.NewRegistry()
+	toolRegistry.Register("n1", &suffixTool{suffix: ":1"})
+	toolRegistry.Register("n2", &suffixTool{suffix: ":2"})
+	toolRegistry.Register("n3", &suffixTool{suffix: ":3"})
+	toolRegistry.Register("n4", &suffixTool{suffix: ":4"})
+
+	chain := map[int]Node{
+		1: {ID: 1, Type: NodeTypeTool, Tool: "n1", Refs: []int{2, 3}},
+		2: {ID: 2, Type: NodeTypeTool, Tool: "n2", Refs: []int{4}},
+		3: {ID: 3, Type: NodeTypeTool, Tool: "n3", Refs: []int{4}},
+		4: {ID: 4, Type: NodeTypeTool, Tool: "n4"},
+	}
+
+	output, err := executeDAG(chain, "start", toolRegistry, llm.NewRegistry(), nil, 0, nil, 0, nil)
+	if err != nil {
+		t.Fatalf("executeDAG() error = %v", err)
+	}
+
+	want := "start:1:2\nstart:1:3:4"
+	if output != want {
+		t.Errorf("executeDAG() = %q, want %q", output, want)
+	}
+}
+
+func TestExecuteDAGErrorAbortsDependents(t *testing.T) {
+	toolRegistry := tools.NewRegistry()
+	toolRegistry.Register("fail", failingTool{})
+	toolRegistry.Register("n2", &suffixTool{suffix: ":2"})
+
+	chain := map[int]Node{
+		1: {ID: 1, Type: NodeTypeTool, Tool: "fail", Refs: []int{2}},
+		2: {ID: 2, Type: NodeTypeTool, Tool: "n2"},
+	}
+
+	_, err := executeDAG(chain, "start", toolRegistry, llm.NewRegistry(), nil, 0, nil, 0, nil)
+	if err == nil {
+		t.Fatal("executeDAG() error = nil, want an error from the failing node")
+	}
+}
+
+func TestExecuteDAGCycleDetected(t *testing.T) {
+	toolRegistry := tools.NewRegistry()
+	toolRegistry.Register("noop", &suffixTool{})
+
+	chain := map[int]Node{
+		1: {ID: 1, Type: NodeTypeTool, Tool: "noop", Refs: []int{2}},
+		2: {ID: 2, Type: NodeTypeTool, Tool: "noop", Refs: []int{1}},
+	}
+
+	if _, err := executeDAG(chain, "start", toolRegistry, llm.NewRegistry(), nil, 0, nil, 0, nil); err == nil {
+		t.Fatal("executeDAG() error = nil, want a cycle detection error")
+	}
 } 
\ No newline at end of file
@@ -0,0 +1,285 @@
+package gendo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"gendo/pkg/config"
+	"gendo/pkg/llm"
+	"gendo/pkg/log"
+	"gendo/pkg/tools"
+)
+
+// reloadDebounce coalesces bursts of filesystem events (editors commonly
+// emit several writes for a single save) into a single reload.
+const reloadDebounce = 200 * time.Millisecond
+
+// graph is the routing table processInput reads per line. Watch swaps it
+// out atomically on reload so a line already in flight keeps using the node
+// set it started with.
+type graph struct {
+	mu               sync.RWMutex
+	nodes            map[int]Node
+	defaultErrorNode int
+}
+
+func (g *graph) snapshot() (map[int]Node, int) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.nodes, g.defaultErrorNode
+}
+
+func (g *graph) swap(nodes map[int]Node, defaultErrorNode int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nodes = nodes
+	g.defaultErrorNode = defaultErrorNode
+}
+
+// llmRegistryBox lets Watch swap in newly ad-hoc-registered LLM backends on
+// reload (see registerNodeBackends) without racing the plain-map
+// llm.Registry against in-flight requests reading it, the same problem
+// graph solves for the node table.
+type llmRegistryBox struct {
+	mu  sync.RWMutex
+	reg llm.Registry
+}
+
+func (b *llmRegistryBox) snapshot() llm.Registry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.reg
+}
+
+func (b *llmRegistryBox) swap(reg llm.Registry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reg = reg
+}
+
+// Watch runs filename like Run, but keeps the process alive and watches the
+// script for changes via fsnotify. On each write, the script is re-parsed
+// and the routing table is swapped in atomically; nodes already processing
+// an input line are unaffected. A script that can't be read is logged and
+// the previous routing table is kept; lines that don't parse are skipped,
+// as in Run, and the table built from the remaining lines is swapped in. As
+// in RunWithBackend, every line logged is tagged with filename as "pipeline".
+func Watch(filename string, model string, backend string) error {
+	logger := log.Default().With("pipeline", filename)
+	logger.Debug("Watching script: %s", filename)
+
+	lines, err := readScriptLines(filename)
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	nodes, inputLines, defaultErrorNode := parseScript(lines, logger)
+
+	g := &graph{nodes: nodes, defaultErrorNode: defaultErrorNode}
+
+	toolRegistry, llmRegistry := NewRegistries(model, backend, nodes, logger)
+	llmBox := &llmRegistryBox{reg: llmRegistry}
+
+	watchConfig(logger)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	// fsnotify watches directories: editors typically replace a file on
+	// save (rename + create) rather than writing it in place, and a watch
+	// on the file itself would miss the new inode.
+	dir := filepath.Dir(filename)
+	if err := watcher.Add(dir); err != nil {
+		return fmt.Errorf("failed to watch %s: %v", dir, err)
+	}
+	addToolBasePathWatches(watcher, loadConfigOverride(model, backend, logger), logger)
+
+	go watchLoop(watcher, filename, model, backend, g, toolRegistry, llmBox, logger)
+
+	logger.Debug("Processing %d script input lines", len(inputLines))
+	for lineID, line := range inputLines {
+		currentNodes, currentErrNode := g.snapshot()
+		if err := processInput(line, currentNodes, toolRegistry, llmBox.snapshot(), 1, currentErrNode, os.Stdout, os.Stderr, logger, nil, lineID, nil, 0); err != nil {
+			return err
+		}
+	}
+
+	logger.Debug("Reading input from stdin in watch mode")
+	scanner := bufio.NewScanner(os.Stdin)
+	for lineID := 0; scanner.Scan(); lineID++ {
+		currentNodes, currentErrNode := g.snapshot()
+		if err := processInput(scanner.Text(), currentNodes, toolRegistry, llmBox.snapshot(), 1, currentErrNode, os.Stdout, os.Stderr, logger, nil, lineID, nil, 0); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// addToolBasePathWatches adds a watch for each configured read/write tool
+// basePath on the shared watcher. watchLoop acts only on events naming the
+// script itself, so a change under a tool basePath does not trigger a
+// reload or any other action. A basePath that doesn't exist yet, or can't be
+// watched, is logged and skipped rather than failing Watch outright, since
+// it's a secondary convenience on top of the script watch that always
+// succeeds first.
+func addToolBasePathWatches(watcher *fsnotify.Watcher, cfg *config.Config, logger *log.Logger) {
+	for _, name := range []string{"read", "write"} {
+		basePath := toolBasePath(cfg, name)
+		if basePath == "" {
+			continue
+		}
+		if err := watcher.Add(basePath); err != nil {
+			logger.Error("Not watching %s tool base path %s: %v", name, basePath, err)
+			continue
+		}
+		logger.Debug("Watching %s tool base path: %s", name, basePath)
+	}
+}
+
+// watchConfig starts watching gendo's config file, if one was found, so that
+// a long-running `gendo -watch` process picks up a change to log_level
+// without needing a restart. Config changes to backend/model still only
+// take effect on the next restart, or the next script reload if the change
+// happens to introduce a node binding not yet registered - see reload and
+// llmRegistryBox, which handle that narrower case. The new level is applied
+// both to the default logger and to logger, Watch's pipeline logger, since
+// a logger derived with With keeps the level it was created with.
+func watchConfig(logger *log.Logger) {
+	cfgSource, err := config.NewSource()
+	if err != nil {
+		log.Error("Config watch disabled: %v", err)
+		return
+	}
+
+	cfgSource.Watch(func(cfg *config.Config) {
+		log.SetVerbose(cfg.LogLevel == "debug")
+		if cfg.LogLevel == "debug" {
+			logger.SetLevel(log.LevelDebug)
+		} else {
+			logger.SetLevel(log.LevelInfo)
+		}
+		log.Debug("Config reloaded: backend=%s model=%s log_level=%s", cfg.Backend, cfg.Model, cfg.LogLevel)
+	})
+}
+
+// watchLoop debounces fsnotify events for filename and reloads g on each
+// settled burst, until the watcher is closed. Events from the tool
+// basePath watches added by addToolBasePathWatches are otherwise ignored
+// here (they don't affect routing), but sharing one watcher for both keeps
+// Watch's fsnotify setup in one place.
+func watchLoop(watcher *fsnotify.Watcher, filename, model, backend string, g *graph, toolRegistry tools.Registry, llmBox *llmRegistryBox, logger *log.Logger) {
+	var debounce *time.Timer
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(filename) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if debounce != nil {
+				debounce.Stop()
+			}
+			debounce = time.AfterFunc(reloadDebounce, func() { reload(filename, model, backend, g, toolRegistry, llmBox, logger) })
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Error("Watcher error: %v", err)
+		}
+	}
+}
+
+// reload re-parses filename and swaps it into g, logging which node IDs
+// were added, removed, or mutated. If filename can't be read, the existing
+// graph is left untouched and the error is logged; lines that don't parse
+// are skipped, so a script with a bad line still replaces the existing
+// graph with whatever nodes did parse. It also re-runs
+// registerNodeBackends against the new node set and swaps the result into
+// llmBox, so a reloaded script that adds a brand-new "llm name ..." or
+// "backend: name ..." binding doesn't fail every request afterwards with
+// "unknown LLM backend" just because that name didn't exist at startup.
+func reload(filename, model, backend string, g *graph, toolRegistry tools.Registry, llmBox *llmRegistryBox, logger *log.Logger) {
+	lines, err := readScriptLines(filename)
+	if err != nil {
+		logger.Error("Reload of %s failed, keeping previous script: %v", filename, err)
+		return
+	}
+
+	newNodes, _, newDefaultErrorNode := parseScript(lines, logger)
+
+	oldNodes, _ := g.snapshot()
+	logNodeDiff(oldNodes, newNodes, logger)
+
+	newLLMRegistry := llm.NewRegistry()
+	for name, backendLLM := range llmBox.snapshot() {
+		newLLMRegistry.Register(name, backendLLM)
+	}
+	cfg := loadConfigOverride(model, backend, logger)
+	registerNodeBackends(newNodes, cfg, toolRegistry, newLLMRegistry, logger)
+	llmBox.swap(newLLMRegistry)
+
+	g.swap(newNodes, newDefaultErrorNode)
+	logger.Debug("Reloaded %s", filename)
+}
+
+// logNodeDiff logs which node IDs were added, removed, or changed between
+// two routing tables.
+func logNodeDiff(oldNodes, newNodes map[int]Node, logger *log.Logger) {
+	for id, newNode := range newNodes {
+		oldNode, existed := oldNodes[id]
+		switch {
+		case !existed:
+			logger.Debug("Node %d added", id)
+		case !nodesEqual(oldNode, newNode):
+			logger.Debug("Node %d changed", id)
+		}
+	}
+	for id := range oldNodes {
+		if _, stillExists := newNodes[id]; !stillExists {
+			logger.Debug("Node %d removed", id)
+		}
+	}
+}
+
+// nodesEqual reports whether two Node values describe the same node,
+// comparing their Refs and BackendChain slices element by element, in order.
+func nodesEqual(a, b Node) bool {
+	if a.ID != b.ID || a.Prompt != b.Prompt || a.Tool != b.Tool || a.Type != b.Type {
+		return false
+	}
+	if a.HTTPMethod != b.HTTPMethod || a.HTTPPath != b.HTTPPath || a.LLM != b.LLM || a.Stream != b.Stream {
+		return false
+	}
+	if len(a.BackendChain) != len(b.BackendChain) {
+		return false
+	}
+	for i := range a.BackendChain {
+		if a.BackendChain[i] != b.BackendChain[i] {
+			return false
+		}
+	}
+	if len(a.Refs) != len(b.Refs) {
+		return false
+	}
+	for i := range a.Refs {
+		if a.Refs[i] != b.Refs[i] {
+			return false
+		}
+	}
+	return true
+}
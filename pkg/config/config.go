@@ -0,0 +1,147 @@
+// Package config provides Gendo's hierarchical configuration, merging, in
+// precedence order: environment variables (GENDO_*), and a gendo.yaml,
+// gendo.toml, or gendo.json file discovered in "./", "$XDG_CONFIG_HOME/gendo/"
+// (or "~/.config/gendo/" if unset), and "/etc/gendo/". CLI flags take the
+// highest precedence and are expected to be applied by the caller on top of
+// the resolved Config (see cmd/gendo/main.go), since Gendo's flag parsing
+// uses the standard library "flag" package rather than pflag.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"gendo/pkg/log"
+)
+
+// ToolConfig holds per-tool options from a config file's [tools.*] table,
+// e.g. a base path for the read/write tools.
+type ToolConfig struct {
+	BasePath string `mapstructure:"base_path"`
+}
+
+// Profile is a named model profile a script can reference by name (e.g.
+// "fast" vs "accurate"), letting one config file describe several
+// backend/model combinations.
+type Profile struct {
+	Backend string `mapstructure:"backend"`
+	Model   string `mapstructure:"model"`
+	APIKey  string `mapstructure:"api_key"`
+	BaseURL string `mapstructure:"base_url"`
+}
+
+// Config is Gendo's fully-resolved configuration.
+type Config struct {
+	Backend  string                `mapstructure:"backend"`
+	Model    string                `mapstructure:"model"`
+	APIKey   string                `mapstructure:"api_key"`
+	BaseURL  string                `mapstructure:"base_url"`
+	GRPCAddr string                `mapstructure:"grpc_addr"`
+	LogLevel string                `mapstructure:"log_level"`
+	Tools    map[string]ToolConfig `mapstructure:"tools"`
+	Profiles map[string]Profile    `mapstructure:"profiles"`
+	// Backends lists additional named gRPC LLM backends to dial at startup
+	// and register into the LLM registry, as "name@target" entries (e.g.
+	// "falcon@unix:/tmp/falcon.sock"), so a script's "llm <name> ..." nodes
+	// can route to them. The "openai" (or "grpc", see GRPCAddr) entry is
+	// always registered separately and needs no manifest entry.
+	Backends []string `mapstructure:"backends"`
+	// OllamaURL is the base address of a local Ollama server, used by the
+	// always-registered "ollama" LLM entry and any "ollama:<model>" node
+	// binding (see gendo.registerNodeBackends).
+	OllamaURL string `mapstructure:"ollama_url"`
+}
+
+// Source is a loaded configuration that can additionally be watched for
+// changes to the backing config file.
+type Source struct {
+	v *viper.Viper
+}
+
+// NewSource builds a Source by merging environment variables with a
+// gendo.yaml/.toml/.json file discovered on the search path described in the
+// package doc. A missing config file is not an error; NewSource falls back
+// to Gendo's existing defaults.
+func NewSource() (*Source, error) {
+	v := viper.New()
+
+	v.SetDefault("backend", "openai")
+	v.SetDefault("model", "bitnet")
+	v.SetDefault("base_url", "http://localhost:9100/v1")
+	v.SetDefault("grpc_addr", "localhost:50051")
+	v.SetDefault("ollama_url", "http://localhost:11434")
+	v.SetDefault("log_level", "info")
+
+	v.SetEnvPrefix("GENDO")
+	v.AutomaticEnv()
+	// Preserve the historical fallback to the plain OpenAI env var names,
+	// in addition to the GENDO_-prefixed ones AutomaticEnv already covers.
+	_ = v.BindEnv("api_key", "GENDO_API_KEY", "OPENAI_API_KEY")
+	_ = v.BindEnv("base_url", "GENDO_API_BASE", "OPENAI_API_BASE", "OPENAI_BASE_URL")
+
+	v.SetConfigName("gendo")
+	v.AddConfigPath(".")
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		v.AddConfigPath(filepath.Join(xdg, "gendo"))
+	} else if home, err := os.UserHomeDir(); err == nil {
+		v.AddConfigPath(filepath.Join(home, ".config", "gendo"))
+	}
+	v.AddConfigPath("/etc/gendo")
+
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, fmt.Errorf("failed to read gendo config: %v", err)
+		}
+		log.Debug("No gendo config file found, using environment and defaults")
+	} else {
+		log.Debug("Loaded gendo config from %s", v.ConfigFileUsed())
+	}
+
+	return &Source{v: v}, nil
+}
+
+// Load is a convenience wrapper around NewSource().Config() for callers that
+// don't need to watch for changes.
+func Load() (*Config, error) {
+	src, err := NewSource()
+	if err != nil {
+		return nil, err
+	}
+	return src.Config()
+}
+
+// Config unmarshals the Source's current settings into a Config.
+func (s *Source) Config() (*Config, error) {
+	var cfg Config
+	if err := s.v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse gendo config: %v", err)
+	}
+	return &cfg, nil
+}
+
+// Watch re-reads the config file whenever it changes on disk and invokes
+// onChange with the newly resolved Config, so a long-running `gendo -watch`
+// process can react to a config change without a restart. What is applied
+// is up to onChange; `gendo -watch` applies only log_level, and changes to
+// backend or model routing are not applied this way. It is a no-op if no
+// config file was found by NewSource, since
+// there is then nothing on disk to watch.
+func (s *Source) Watch(onChange func(*Config)) {
+	if s.v.ConfigFileUsed() == "" {
+		return
+	}
+
+	s.v.OnConfigChange(func(_ fsnotify.Event) {
+		cfg, err := s.Config()
+		if err != nil {
+			log.Error("Failed to reload gendo config: %v", err)
+			return
+		}
+		onChange(cfg)
+	})
+	s.v.WatchConfig()
+}
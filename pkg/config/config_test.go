@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withWorkingDir temporarily changes the process's working directory, for
+// tests that rely on NewSource's "." config search path.
+func withWorkingDir(t *testing.T, dir string) {
+	t.Helper()
+	old, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() error: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir(%q) error: %v", dir, err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(old) })
+}
+
+func TestLoadDefaults(t *testing.T) {
+	withWorkingDir(t, t.TempDir())
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.Backend != "openai" {
+		t.Errorf("Backend = %q, want %q", cfg.Backend, "openai")
+	}
+	if cfg.Model != "bitnet" {
+		t.Errorf("Model = %q, want %q", cfg.Model, "bitnet")
+	}
+	if cfg.BaseURL != "http://localhost:9100/v1" {
+		t.Errorf("BaseURL = %q, want %q", cfg.BaseURL, "http://localhost:9100/v1")
+	}
+}
+
+func TestLoadEnvOverride(t *testing.T) {
+	withWorkingDir(t, t.TempDir())
+	t.Setenv("GENDO_MODEL", "gpt-4o")
+	t.Setenv("GENDO_BACKEND", "grpc")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.Model != "gpt-4o" {
+		t.Errorf("Model = %q, want %q", cfg.Model, "gpt-4o")
+	}
+	if cfg.Backend != "grpc" {
+		t.Errorf("Backend = %q, want %q", cfg.Backend, "grpc")
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	withWorkingDir(t, dir)
+
+	contents := "backend: grpc\nmodel: llama3\ntools:\n  read:\n    base_path: /data\n"
+	if err := os.WriteFile(filepath.Join(dir, "gendo.yaml"), []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write gendo.yaml: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.Backend != "grpc" {
+		t.Errorf("Backend = %q, want %q", cfg.Backend, "grpc")
+	}
+	if cfg.Model != "llama3" {
+		t.Errorf("Model = %q, want %q", cfg.Model, "llama3")
+	}
+	if cfg.Tools["read"].BasePath != "/data" {
+		t.Errorf("Tools[read].BasePath = %q, want %q", cfg.Tools["read"].BasePath, "/data")
+	}
+}
+
+func TestSourceWatchNoConfigFileIsNoop(t *testing.T) {
+	withWorkingDir(t, t.TempDir())
+
+	src, err := NewSource()
+	if err != nil {
+		t.Fatalf("NewSource() error: %v", err)
+	}
+
+	// Should not panic or block when there is nothing to watch.
+	src.Watch(func(*Config) {
+		t.Error("onChange should not be called when no config file exists")
+	})
+}
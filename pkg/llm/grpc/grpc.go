@@ -0,0 +1,111 @@
+// Package grpc implements a gRPC-backed llm.LLM, letting Gendo talk to
+// local model servers (llama.cpp, bitnet, whisper, bert embeddings, ...)
+// hosted as gRPC subprocesses instead of only OpenAI-compatible HTTP.
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"gendo/pkg/llm/grpc/proto"
+	"gendo/pkg/log"
+)
+
+// dialTimeout bounds how long New waits for the initial connection.
+const dialTimeout = 5 * time.Second
+
+// pool caches one *grpc.ClientConn per target address so that repeated
+// lookups of the same backend (e.g. multiple nodes routed to the same
+// model) reuse a single connection instead of dialing again.
+type pool struct {
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+var connPool = &pool{conns: make(map[string]*grpc.ClientConn)}
+
+func (p *pool) get(target string, dialOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if conn, ok := p.conns[target]; ok {
+		return conn, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock()}, dialOpts...)
+	conn, err := grpc.DialContext(ctx, target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial gRPC backend %q: %v", target, err)
+	}
+
+	p.conns[target] = conn
+	return conn, nil
+}
+
+// LLM implements the llm.LLM interface over a GendoLLM gRPC service, such as
+// a llama.cpp or bitnet server started via Spawn.
+type LLM struct {
+	target string
+	client proto.GendoLLMClient
+	proc   *managedProcess // nil unless the backend was started via Spawn
+}
+
+// New dials (or reuses a pooled connection to) a GendoLLM server already
+// listening at target, e.g. "localhost:50051" or "unix:/tmp/falcon.sock".
+func New(target string) (*LLM, error) {
+	conn, err := connPool.get(target)
+	if err != nil {
+		return nil, err
+	}
+	return &LLM{target: target, client: proto.NewGendoLLMClient(conn)}, nil
+}
+
+// Process implements the llm.LLM interface
+func (l *LLM) Process(prompt, input string) (string, error) {
+	log.Debug("Processing with gRPC LLM backend %q - Prompt: %q, Input: %q", l.target, prompt, input)
+
+	resp, err := l.client.Predict(context.Background(), &proto.PredictRequest{Prompt: prompt, Input: input})
+	if err != nil {
+		log.Debug("gRPC backend %q Predict failed: %v", l.target, err)
+		return "", fmt.Errorf("grpc backend %q: %v", l.target, err)
+	}
+
+	log.Debug("gRPC backend %q returned: %q", l.target, resp.Text)
+	return resp.Text, nil
+}
+
+// Embed returns a vector embedding for input, for backends (e.g. a bert
+// server) that implement GendoLLM.Embed.
+func (l *LLM) Embed(input string) ([]float32, error) {
+	resp, err := l.client.Embed(context.Background(), &proto.EmbedRequest{Input: input})
+	if err != nil {
+		return nil, fmt.Errorf("grpc backend %q: %v", l.target, err)
+	}
+	return resp.Vector, nil
+}
+
+// Health reports whether the backend considers itself ready.
+func (l *LLM) Health() (bool, error) {
+	resp, err := l.client.Health(context.Background(), &proto.HealthRequest{})
+	if err != nil {
+		return false, fmt.Errorf("grpc backend %q: %v", l.target, err)
+	}
+	return resp.Ready, nil
+}
+
+// Close releases the managed child process, if this LLM was created via
+// Spawn. It is a no-op for backends dialed with New.
+func (l *LLM) Close() error {
+	if l.proc == nil {
+		return nil
+	}
+	return l.proc.stop()
+}
@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"gendo/pkg/llm/grpc/proto"
+)
+
+const bufSize = 1024 * 1024
+
+// fakeBackend is a minimal in-process GendoLLM implementation used to test
+// the client without spawning a real model server.
+type fakeBackend struct {
+	proto.UnimplementedGendoLLMServer
+}
+
+func (fakeBackend) Predict(ctx context.Context, req *proto.PredictRequest) (*proto.PredictResponse, error) {
+	return &proto.PredictResponse{Text: req.Prompt + ":" + req.Input}, nil
+}
+
+func (fakeBackend) Embed(ctx context.Context, req *proto.EmbedRequest) (*proto.EmbedResponse, error) {
+	return &proto.EmbedResponse{Vector: []float32{1, 2, 3}}, nil
+}
+
+func (fakeBackend) Health(ctx context.Context, req *proto.HealthRequest) (*proto.HealthResponse, error) {
+	return &proto.HealthResponse{Ready: true}, nil
+}
+
+// newBufconnClient starts fakeBackend on an in-memory bufconn listener and
+// returns an LLM dialed against it over that listener.
+func newBufconnClient(t *testing.T) *LLM {
+	t.Helper()
+
+	lis := bufconn.Listen(bufSize)
+	grpcServer := grpc.NewServer()
+	proto.RegisterGendoLLMServer(grpcServer, fakeBackend{})
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			t.Logf("bufconn server exited: %v", err)
+		}
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &LLM{target: "bufnet", client: proto.NewGendoLLMClient(conn)}
+}
+
+func TestLLMProcess(t *testing.T) {
+	llm := newBufconnClient(t)
+
+	got, err := llm.Process("system", "hello")
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if want := "system:hello"; got != want {
+		t.Errorf("Process() = %q, want %q", got, want)
+	}
+}
+
+func TestLLMEmbed(t *testing.T) {
+	llm := newBufconnClient(t)
+
+	vec, err := llm.Embed("hello")
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(vec) != 3 {
+		t.Errorf("Embed() returned %d dimensions, want 3", len(vec))
+	}
+}
+
+func TestLLMHealth(t *testing.T) {
+	llm := newBufconnClient(t)
+
+	ready, err := llm.Health()
+	if err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if !ready {
+		t.Error("Health() = false, want true")
+	}
+}
@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"gendo/pkg/log"
+)
+
+// processStartTimeout bounds how long Spawn waits for the backend binary to
+// come up and accept connections before giving up.
+const processStartTimeout = 10 * time.Second
+
+// managedProcess wraps a backend binary (llama.cpp, bitnet, whisper, ...)
+// started and owned by Gendo, so its stdio can be captured and it can be
+// torn down when the LLM is closed.
+type managedProcess struct {
+	cmd *exec.Cmd
+}
+
+// Spawn starts the backend binary at path with args, captures its stdout and
+// stderr into the Gendo log at debug level, waits up to
+// processStartTimeout for it to listen on target, and returns an LLM dialed
+// against it. The child process is killed when the returned LLM is closed.
+func Spawn(path string, args []string, target string) (*LLM, error) {
+	cmd := exec.Command(path, args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach stdout to %q: %v", path, err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach stderr to %q: %v", path, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start backend %q: %v", path, err)
+	}
+
+	go streamToLog(path, "stdout", stdout)
+	go streamToLog(path, "stderr", stderr)
+
+	llm, err := dialWithRetry(target, processStartTimeout)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		return nil, err
+	}
+
+	llm.proc = &managedProcess{cmd: cmd}
+	return llm, nil
+}
+
+// dialWithRetry repeatedly attempts New(target) until it succeeds or
+// timeout elapses, since a freshly spawned backend may not be listening yet.
+func dialWithRetry(target string, timeout time.Duration) (*LLM, error) {
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		llm, err := New(target)
+		if err == nil {
+			return llm, nil
+		}
+		lastErr = err
+		time.Sleep(200 * time.Millisecond)
+	}
+	return nil, fmt.Errorf("backend at %q did not become ready within %s: %v", target, timeout, lastErr)
+}
+
+// streamToLog copies lines from a backend's stdio into the Gendo debug log,
+// tagged with the binary name and stream so mixed backend output stays
+// attributable.
+func streamToLog(name, stream string, r interface{ Read([]byte) (int, error) }) {
+	scanner := bufio.NewScanner(&readerAdapter{r})
+	for scanner.Scan() {
+		log.Debug("[%s %s] %s", name, stream, scanner.Text())
+	}
+}
+
+// readerAdapter adapts the minimal Read-only interface streamToLog accepts
+// (so it can take either an io.ReadCloser pipe or a plain io.Reader) to
+// io.Reader for bufio.Scanner.
+type readerAdapter struct {
+	r interface{ Read([]byte) (int, error) }
+}
+
+func (a *readerAdapter) Read(p []byte) (int, error) {
+	return a.r.Read(p)
+}
+
+// stop terminates the managed backend process.
+func (p *managedProcess) stop() error {
+	if p.cmd.Process == nil {
+		return nil
+	}
+	return p.cmd.Process.Kill()
+}
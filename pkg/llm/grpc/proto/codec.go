@@ -0,0 +1,41 @@
+package proto
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// codecName is the gRPC content-subtype this package's messages are sent
+// under; client calls opt into it via grpc.CallContentSubtype(codecName)
+// (see gendollm_grpc.pb.go), and the server side picks the matching codec
+// back up from the request's content-type automatically once it's
+// registered, with no server-side option needed.
+const codecName = "gendollm-wire"
+
+func init() {
+	encoding.RegisterCodec(wireCodec{})
+}
+
+// wireCodec adapts wireMessage's hand-written marshaling (see wire.go) to
+// the grpc encoding.Codec interface, since PredictRequest and friends don't
+// implement proto.Message and so can't use grpc's default codec.
+type wireCodec struct{}
+
+func (wireCodec) Name() string { return codecName }
+
+func (wireCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(wireMessage)
+	if !ok {
+		return nil, fmt.Errorf("gendollm-wire codec: %T does not implement wireMessage", v)
+	}
+	return m.marshalWire()
+}
+
+func (wireCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(wireMessage)
+	if !ok {
+		return fmt.Errorf("gendollm-wire codec: %T does not implement wireMessage", v)
+	}
+	return m.unmarshalWire(data)
+}
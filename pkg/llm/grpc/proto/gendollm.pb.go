@@ -0,0 +1,133 @@
+// Package proto holds the GendoLLM message and service types described by
+// gendollm.proto. These are hand-written rather than protoc-gen-go output:
+// this environment has no protoc available, so rather than ship a fake
+// "DO NOT EDIT" header over structs that don't actually implement
+// proto.Message (and would panic/fail to marshal under the default gRPC
+// codec), the wire encoding is implemented directly in wire.go and
+// registered as its own grpc codec (see codec.go). Keep this file's fields
+// and field numbers in sync with gendollm.proto by hand; regenerating with
+// protoc, if it becomes available, should produce a drop-in replacement.
+package proto
+
+// PredictRequest is the request message for GendoLLM.Predict.
+type PredictRequest struct {
+	Prompt string
+	Input  string
+}
+
+func (m *PredictRequest) marshalWire() ([]byte, error) {
+	var buf []byte
+	buf = appendString(buf, 1, m.Prompt)
+	buf = appendString(buf, 2, m.Input)
+	return buf, nil
+}
+
+func (m *PredictRequest) unmarshalWire(data []byte) error {
+	return walkFields(data,
+		func(fieldNum int, v uint64) error { return nil },
+		func(fieldNum int, payload []byte) error {
+			switch fieldNum {
+			case 1:
+				m.Prompt = string(payload)
+			case 2:
+				m.Input = string(payload)
+			}
+			return nil
+		})
+}
+
+// PredictResponse is the response message for GendoLLM.Predict.
+type PredictResponse struct {
+	Text string
+}
+
+func (m *PredictResponse) marshalWire() ([]byte, error) {
+	return appendString(nil, 1, m.Text), nil
+}
+
+func (m *PredictResponse) unmarshalWire(data []byte) error {
+	return walkFields(data,
+		func(fieldNum int, v uint64) error { return nil },
+		func(fieldNum int, payload []byte) error {
+			if fieldNum == 1 {
+				m.Text = string(payload)
+			}
+			return nil
+		})
+}
+
+// EmbedRequest is the request message for GendoLLM.Embed.
+type EmbedRequest struct {
+	Input string
+}
+
+func (m *EmbedRequest) marshalWire() ([]byte, error) {
+	return appendString(nil, 1, m.Input), nil
+}
+
+func (m *EmbedRequest) unmarshalWire(data []byte) error {
+	return walkFields(data,
+		func(fieldNum int, v uint64) error { return nil },
+		func(fieldNum int, payload []byte) error {
+			if fieldNum == 1 {
+				m.Input = string(payload)
+			}
+			return nil
+		})
+}
+
+// EmbedResponse is the response message for GendoLLM.Embed.
+type EmbedResponse struct {
+	Vector []float32
+}
+
+func (m *EmbedResponse) marshalWire() ([]byte, error) {
+	return appendFloat32Packed(nil, 1, m.Vector), nil
+}
+
+func (m *EmbedResponse) unmarshalWire(data []byte) error {
+	return walkFields(data,
+		func(fieldNum int, v uint64) error { return nil },
+		func(fieldNum int, payload []byte) error {
+			if fieldNum != 1 {
+				return nil
+			}
+			vec, err := decodeFloat32Packed(payload)
+			if err != nil {
+				return err
+			}
+			m.Vector = vec
+			return nil
+		})
+}
+
+// HealthRequest is the (empty) request message for GendoLLM.Health.
+type HealthRequest struct{}
+
+func (m *HealthRequest) marshalWire() ([]byte, error) {
+	return nil, nil
+}
+
+func (m *HealthRequest) unmarshalWire(data []byte) error {
+	return nil
+}
+
+// HealthResponse is the response message for GendoLLM.Health.
+type HealthResponse struct {
+	Ready bool
+}
+
+func (m *HealthResponse) marshalWire() ([]byte, error) {
+	return appendBool(nil, 1, m.Ready), nil
+}
+
+func (m *HealthResponse) unmarshalWire(data []byte) error {
+	return walkFields(data,
+		func(fieldNum int, v uint64) error {
+			if fieldNum == 1 {
+				m.Ready = v != 0
+			}
+			return nil
+		},
+		func(fieldNum int, payload []byte) error { return nil })
+}
@@ -0,0 +1,142 @@
+// Hand-written GendoLLM client/server stubs matching gendollm.proto; see
+// gendollm.pb.go's package comment for why these aren't protoc-gen-go-grpc
+// output. Messages are marshaled via the "gendollm-wire" codec (codec.go),
+// not grpc's default proto codec.
+
+package proto
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GendoLLMClient is the client API for the GendoLLM service.
+type GendoLLMClient interface {
+	Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error)
+	Embed(ctx context.Context, in *EmbedRequest, opts ...grpc.CallOption) (*EmbedResponse, error)
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+}
+
+type gendoLLMClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewGendoLLMClient wraps a ClientConn with the generated GendoLLM client.
+func NewGendoLLMClient(cc grpc.ClientConnInterface) GendoLLMClient {
+	return &gendoLLMClient{cc}
+}
+
+func (c *gendoLLMClient) Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error) {
+	out := new(PredictResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+	if err := c.cc.Invoke(ctx, "/gendollm.GendoLLM/Predict", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gendoLLMClient) Embed(ctx context.Context, in *EmbedRequest, opts ...grpc.CallOption) (*EmbedResponse, error) {
+	out := new(EmbedResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+	if err := c.cc.Invoke(ctx, "/gendollm.GendoLLM/Embed", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gendoLLMClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+	if err := c.cc.Invoke(ctx, "/gendollm.GendoLLM/Health", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GendoLLMServer is the server API for the GendoLLM service. Backend
+// binaries implement this interface and pass it to RegisterGendoLLMServer.
+type GendoLLMServer interface {
+	Predict(context.Context, *PredictRequest) (*PredictResponse, error)
+	Embed(context.Context, *EmbedRequest) (*EmbedResponse, error)
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+}
+
+// UnimplementedGendoLLMServer can be embedded in a server implementation to
+// get forward-compatible default (unimplemented) methods for free.
+type UnimplementedGendoLLMServer struct{}
+
+func (UnimplementedGendoLLMServer) Predict(context.Context, *PredictRequest) (*PredictResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Predict not implemented")
+}
+func (UnimplementedGendoLLMServer) Embed(context.Context, *EmbedRequest) (*EmbedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Embed not implemented")
+}
+func (UnimplementedGendoLLMServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+
+// RegisterGendoLLMServer registers srv on the given gRPC server under the
+// GendoLLM service name.
+func RegisterGendoLLMServer(s grpc.ServiceRegistrar, srv GendoLLMServer) {
+	s.RegisterService(&gendoLLMServiceDesc, srv)
+}
+
+var gendoLLMServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gendollm.GendoLLM",
+	HandlerType: (*GendoLLMServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Predict", Handler: predictHandler},
+		{MethodName: "Embed", Handler: embedHandler},
+		{MethodName: "Health", Handler: healthHandler},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gendollm.proto",
+}
+
+func predictHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PredictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GendoLLMServer).Predict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/gendollm.GendoLLM/Predict"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GendoLLMServer).Predict(ctx, req.(*PredictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func embedHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmbedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GendoLLMServer).Embed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/gendollm.GendoLLM/Embed"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GendoLLMServer).Embed(ctx, req.(*EmbedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func healthHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GendoLLMServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/gendollm.GendoLLM/Health"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GendoLLMServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
@@ -0,0 +1,145 @@
+package proto
+
+import (
+	"fmt"
+	"math"
+)
+
+// wireMessage is implemented by every message type in this package so that
+// wireCodec (see codec.go) can marshal/unmarshal them without relying on the
+// protoreflect machinery a real protoc-gen-go output would provide.
+type wireMessage interface {
+	marshalWire() ([]byte, error)
+	unmarshalWire([]byte) error
+}
+
+// The functions below implement just enough of the proto3 wire format
+// (varints, length-delimited fields, and packed repeated fixed32) to
+// round-trip the message types declared in gendollm.pb.go; they are not a
+// general-purpose encoder.
+
+func appendVarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+func appendTag(buf []byte, fieldNum, wireType int) []byte {
+	return appendVarint(buf, uint64(fieldNum)<<3|uint64(wireType))
+}
+
+// appendString encodes a proto3 string field, omitting it entirely when
+// empty (the wire-format convention for unset scalar fields).
+func appendString(buf []byte, fieldNum int, s string) []byte {
+	if s == "" {
+		return buf
+	}
+	buf = appendTag(buf, fieldNum, 2)
+	buf = appendVarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// appendBool encodes a proto3 bool field, omitting it when false.
+func appendBool(buf []byte, fieldNum int, b bool) []byte {
+	if !b {
+		return buf
+	}
+	buf = appendTag(buf, fieldNum, 0)
+	return appendVarint(buf, 1)
+}
+
+// appendFloat32Packed encodes a packed repeated float field: one
+// length-delimited run of consecutive little-endian 4-byte values, with no
+// per-element tag.
+func appendFloat32Packed(buf []byte, fieldNum int, vs []float32) []byte {
+	if len(vs) == 0 {
+		return buf
+	}
+	buf = appendTag(buf, fieldNum, 2)
+	buf = appendVarint(buf, uint64(len(vs)*4))
+	for _, v := range vs {
+		bits := math.Float32bits(v)
+		buf = append(buf, byte(bits), byte(bits>>8), byte(bits>>16), byte(bits>>24))
+	}
+	return buf
+}
+
+func decodeFloat32Packed(payload []byte) ([]float32, error) {
+	if len(payload)%4 != 0 {
+		return nil, fmt.Errorf("wire: packed float32 payload length %d not a multiple of 4", len(payload))
+	}
+	vs := make([]float32, 0, len(payload)/4)
+	for i := 0; i < len(payload); i += 4 {
+		bits := uint32(payload[i]) | uint32(payload[i+1])<<8 | uint32(payload[i+2])<<16 | uint32(payload[i+3])<<24
+		vs = append(vs, math.Float32frombits(bits))
+	}
+	return vs, nil
+}
+
+// readVarint decodes a varint from the start of buf, returning its value
+// and the number of bytes it consumed.
+func readVarint(buf []byte) (uint64, int, error) {
+	var v uint64
+	var shift uint
+	for i := 0; i < len(buf); i++ {
+		b := buf[i]
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v, i + 1, nil
+		}
+		shift += 7
+		if shift > 63 {
+			return 0, 0, fmt.Errorf("wire: varint too long")
+		}
+	}
+	return 0, 0, fmt.Errorf("wire: truncated varint")
+}
+
+// walkFields decodes every (field number, wire type) pair in buf in order,
+// calling onVarint for wire type 0 fields (with the decoded value) and
+// onBytes for wire type 2 fields (with the raw length-delimited payload,
+// covering both proto3 strings/bytes and packed repeated scalars). Fields
+// using any other wire type are not produced by this package's encoder and
+// are rejected.
+func walkFields(buf []byte, onVarint func(fieldNum int, v uint64) error, onBytes func(fieldNum int, payload []byte) error) error {
+	for len(buf) > 0 {
+		tag, n, err := readVarint(buf)
+		if err != nil {
+			return err
+		}
+		buf = buf[n:]
+		fieldNum := int(tag >> 3)
+		wireType := int(tag & 0x7)
+
+		switch wireType {
+		case 0:
+			v, n, err := readVarint(buf)
+			if err != nil {
+				return err
+			}
+			buf = buf[n:]
+			if err := onVarint(fieldNum, v); err != nil {
+				return err
+			}
+		case 2:
+			length, n, err := readVarint(buf)
+			if err != nil {
+				return err
+			}
+			buf = buf[n:]
+			if uint64(len(buf)) < length {
+				return fmt.Errorf("wire: truncated length-delimited field %d", fieldNum)
+			}
+			payload := buf[:length]
+			buf = buf[length:]
+			if err := onBytes(fieldNum, payload); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("wire: unsupported wire type %d for field %d", wireType, fieldNum)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Package server provides the minimal scaffolding a standalone backend
+// binary (llama.cpp, bitnet, whisper, bert-embeddings, ...) needs to speak
+// Gendo's gRPC LLM protocol: implement proto.GendoLLMServer and call Serve.
+package server
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+
+	"gendo/pkg/llm/grpc/proto"
+	"gendo/pkg/log"
+)
+
+// Serve listens on network/address (e.g. "tcp", "localhost:50051" or "unix",
+// "/tmp/falcon.sock") and blocks serving srv until the listener fails or the
+// process is killed. A backend binary is typically just:
+//
+//	func main() {
+//		srv := &myBackend{}
+//		if err := server.Serve("tcp", ":50051", srv); err != nil {
+//			log.Error("backend exited: %v", err)
+//			os.Exit(1)
+//		}
+//	}
+func Serve(network, address string, srv proto.GendoLLMServer) error {
+	lis, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+
+	grpcServer := grpc.NewServer()
+	proto.RegisterGendoLLMServer(grpcServer, srv)
+
+	log.Debug("gRPC LLM backend listening on %s %s", network, address)
+	return grpcServer.Serve(lis)
+}
@@ -9,6 +9,46 @@ type LLM interface {
 	Process(prompt, input string) (string, error)
 }
 
+// Token is a single chunk of a streaming LLM response, emitted by
+// StreamingLLM.ProcessStream as a model generates text. The channel closes
+// after a Token with Done true, or one carrying a non-nil Err, is sent.
+type Token struct {
+	Text string
+	Err  error
+	Done bool
+}
+
+// StreamingLLM is an optional extension an LLM can implement to emit its
+// response incrementally (e.g. over an HTTP or gRPC streaming call) instead
+// of blocking until the full text is generated. LLMs that don't implement it
+// are adapted by buffering, see ProcessStream.
+type StreamingLLM interface {
+	LLM
+
+	// ProcessStream takes a system prompt and user input, returns a channel
+	// of Tokens as the model generates them.
+	ProcessStream(prompt, input string) (<-chan Token, error)
+}
+
+// ProcessStream adapts any LLM to the streaming Token-channel shape,
+// calling l.ProcessStream directly if l implements StreamingLLM, or
+// buffering l.Process's result into a single Token otherwise.
+func ProcessStream(l LLM, prompt, input string) (<-chan Token, error) {
+	if sl, ok := l.(StreamingLLM); ok {
+		return sl.ProcessStream(prompt, input)
+	}
+
+	result, err := l.Process(prompt, input)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Token, 1)
+	ch <- Token{Text: result, Done: true}
+	close(ch)
+	return ch, nil
+}
+
 // Registry is a map of LLM names to their implementations
 type Registry map[string]LLM
 
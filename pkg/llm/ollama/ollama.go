@@ -0,0 +1,130 @@
+// Package ollama implements the llm.LLM interface against a local Ollama
+// server's /api/chat endpoint, letting a Gendo script route a node to a
+// locally-hosted model (e.g. "ollama:llama3") alongside OpenAI and gRPC
+// backends.
+package ollama
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"gendo/pkg/config"
+	"gendo/pkg/log"
+)
+
+// defaultBaseURL is Ollama's standard local listen address.
+const defaultBaseURL = "http://localhost:11434"
+
+// defaultModel is used when neither the CLI model flag nor cfg.Model names
+// one, matching Ollama's own quickstart default.
+const defaultModel = "llama3"
+
+// LLM implements the llm.LLM interface for a local Ollama server.
+type LLM struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// message is a single chat turn in Ollama's /api/chat request/response body.
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest is the request body for Ollama's /api/chat endpoint.
+type chatRequest struct {
+	Model    string    `json:"model"`
+	Messages []message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+// chatResponse is the non-streaming response body for /api/chat.
+type chatResponse struct {
+	Message message `json:"message"`
+}
+
+// New creates a new Ollama LLM. baseURL and model default to Ollama's usual
+// local address and the "llama3" quickstart model when empty, falling back
+// to the GENDO_OLLAMA_URL environment variable for baseURL first.
+func New(baseURL, model string) *LLM {
+	if baseURL == "" {
+		baseURL = os.Getenv("GENDO_OLLAMA_URL")
+		if baseURL == "" {
+			baseURL = defaultBaseURL
+		}
+	}
+	if model == "" {
+		model = defaultModel
+	}
+
+	return &LLM{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      model,
+		httpClient: &http.Client{},
+	}
+}
+
+// NewFromConfig creates a new Ollama LLM from an already-resolved
+// config.Config, rather than re-reading environment variables itself. Use
+// this in preference to New when a config.Config is already in hand (see
+// gendo.NewRegistries), since Config has already merged CLI overrides,
+// environment variables, and a config file.
+func NewFromConfig(cfg *config.Config) *LLM {
+	return New(cfg.OllamaURL, cfg.Model)
+}
+
+// Process sends prompt and input to Ollama as the system and user messages
+// of a single, non-streaming chat request and returns the assistant's reply.
+func (l *LLM) Process(prompt, input string) (string, error) {
+	log.Debug("Processing with Ollama LLM - Model: %s, Prompt: %q, Input: %q", l.model, prompt, input)
+
+	reqBody := chatRequest{
+		Model: l.model,
+		Messages: []message{
+			{Role: "system", Content: prompt},
+			{Role: "user", Content: input},
+		},
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", l.baseURL+"/api/chat", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	log.Debug("Sending request to %s with body: %s", req.URL.String(), string(jsonData))
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call Ollama API: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %v", err)
+	}
+	log.Debug("Response from Ollama API: %s", string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var chatResp chatResponse
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&chatResp); err != nil {
+		return "", fmt.Errorf("failed to decode Ollama response: %v", err)
+	}
+
+	log.Debug("Ollama LLM returned: %q", chatResp.Message.Content)
+	return chatResp.Message.Content, nil
+}
@@ -0,0 +1,78 @@
+package ollama
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestLLM(t *testing.T, handler http.HandlerFunc) *LLM {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(srv.URL, "test-model")
+}
+
+func TestProcessReturnsMessageContent(t *testing.T) {
+	var gotReq chatRequest
+	llm := newTestLLM(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/chat" {
+			t.Fatalf("request = %s %s, want POST /api/chat", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotReq); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(chatResponse{Message: message{Role: "assistant", Content: "the answer"}})
+	})
+
+	got, err := llm.Process("system prompt", "user input")
+	if err != nil {
+		t.Fatalf("Process() error: %v", err)
+	}
+	if got != "the answer" {
+		t.Errorf("Process() = %q, want %q", got, "the answer")
+	}
+
+	if gotReq.Model != "test-model" {
+		t.Errorf("request Model = %q, want %q", gotReq.Model, "test-model")
+	}
+	if gotReq.Stream {
+		t.Error("request Stream = true, want false (no streaming support)")
+	}
+	if len(gotReq.Messages) != 2 || gotReq.Messages[0].Role != "system" || gotReq.Messages[0].Content != "system prompt" ||
+		gotReq.Messages[1].Role != "user" || gotReq.Messages[1].Content != "user input" {
+		t.Errorf("request Messages = %+v, want system/user pair", gotReq.Messages)
+	}
+}
+
+func TestProcessNonOKStatusReturnsError(t *testing.T) {
+	llm := newTestLLM(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	})
+
+	_, err := llm.Process("system prompt", "user input")
+	if err == nil {
+		t.Fatal("Process() error = nil, want error for non-200 status")
+	}
+}
+
+func TestNewFallsBackToDefaults(t *testing.T) {
+	llm := New("", "")
+	if llm.baseURL != defaultBaseURL {
+		t.Errorf("baseURL = %q, want %q", llm.baseURL, defaultBaseURL)
+	}
+	if llm.model != defaultModel {
+		t.Errorf("model = %q, want %q", llm.model, defaultModel)
+	}
+}
+
+func TestNewEnvOverridesBaseURL(t *testing.T) {
+	t.Setenv("GENDO_OLLAMA_URL", "http://example.invalid:1234")
+	llm := New("", "")
+	if llm.baseURL != "http://example.invalid:1234" {
+		t.Errorf("baseURL = %q, want env override", llm.baseURL)
+	}
+}
@@ -1,7 +1,8 @@
 // Package openai implements the OpenAI language model integration for Gendo.
 // It provides functionality to interact with OpenAI's API, supporting
 // configurable models, API keys, and base URLs. The package handles
-// authentication, request formatting, and response parsing.
+// authentication, request formatting, response parsing, and native
+// function-calling against a tools.Registry.
 package openai
 
 import (
@@ -13,40 +14,74 @@ import (
 	"os"
 	"strings"
 
+	"gendo/pkg/config"
 	"gendo/pkg/log"
+	"gendo/pkg/tools"
 )
 
+// maxToolIterations bounds how many tool-call round trips Process will make
+// for a single request, guarding against a model that never stops calling
+// tools.
+const maxToolIterations = 8
+
 // LLM implements the llm.LLM interface for OpenAI
 type LLM struct {
 	apiKey     string
 	baseURL    string
 	model      string
 	httpClient *http.Client
+	tools      tools.Registry
 }
 
 // Message represents a chat message
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string     `json:"role"`
+	Content    string     `json:"content,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+// ToolCall represents a single tool invocation requested by the model
+type ToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// ToolDefinition describes a tool in the OpenAI function-calling format
+type ToolDefinition struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition is the function body of a ToolDefinition
+type FunctionDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
 }
 
 // Request represents the request body for OpenAI API
 type Request struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
+	Model      string           `json:"model"`
+	Messages   []Message        `json:"messages"`
+	Tools      []ToolDefinition `json:"tools,omitempty"`
+	ToolChoice string           `json:"tool_choice,omitempty"`
 }
 
 // Response represents the response from OpenAI API
 type Response struct {
 	Choices []struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
+		Message Message `json:"message"`
 	} `json:"choices"`
 }
 
-// New creates a new OpenAI LLM
-func New(apiKey string, cliModel string) *LLM {
+// New creates a new OpenAI LLM. toolRegistry may be nil, in which case
+// Process behaves exactly as before and never sends a "tools" field.
+func New(apiKey string, cliModel string, toolRegistry tools.Registry) *LLM {
 	// Try GENDO_API_KEY first, then fall back to OPENAI_API_KEY
 	if apiKey == "" {
 		apiKey = os.Getenv("GENDO_API_KEY")
@@ -82,7 +117,62 @@ func New(apiKey string, cliModel string) *LLM {
 		baseURL:    baseURL,
 		model:      model,
 		httpClient: &http.Client{},
+		tools:      toolRegistry,
+	}
+}
+
+// NewFromConfig creates a new OpenAI LLM from an already-resolved
+// config.Config, rather than re-reading environment variables itself. Use
+// this in preference to New when a config.Config is already in hand (see
+// gendo.NewRegistries), since Config has already merged CLI overrides,
+// environment variables, and a config file.
+func NewFromConfig(cfg *config.Config, toolRegistry tools.Registry) *LLM {
+	return &LLM{
+		apiKey:     cfg.APIKey,
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		model:      cfg.Model,
+		httpClient: &http.Client{},
+		tools:      toolRegistry,
+	}
+}
+
+// toolDefinitions builds the OpenAI "tools" array from the registry, skipping
+// tools that don't implement tools.SchemaTool since they have no declared
+// parameter shape to advertise.
+func (l *LLM) toolDefinitions() []ToolDefinition {
+	var defs []ToolDefinition
+	for name, t := range l.tools {
+		schemaTool, ok := t.(tools.SchemaTool)
+		if !ok {
+			continue
+		}
+		defs = append(defs, ToolDefinition{
+			Type: "function",
+			Function: FunctionDefinition{
+				Name:        name,
+				Description: schemaTool.Description(),
+				Parameters:  schemaTool.Schema(),
+			},
+		})
 	}
+	return defs
+}
+
+// callTool invokes a registered tool by name with the raw JSON arguments the
+// model produced, and returns the text to feed back as the tool message.
+func (l *LLM) callTool(name, arguments string) string {
+	tool := l.tools.Get(name)
+	if tool == nil {
+		log.Debug("Model requested unknown tool %q", name)
+		return fmt.Sprintf("error: unknown tool %q", name)
+	}
+	log.Debug("Invoking tool %q with arguments: %s", name, arguments)
+	result, err := tool.Process(arguments)
+	if err != nil {
+		log.Debug("Tool %q failed: %v", name, err)
+		return fmt.Sprintf("error: %v", err)
+	}
+	return result
 }
 
 // Process implements the llm.LLM interface
@@ -94,24 +184,58 @@ func (l *LLM) Process(prompt, input string) (string, error) {
 		return input, nil
 	}
 
-	reqBody := Request{
-		Model: l.model,
-		Messages: []Message{
-			{Role: "system", Content: prompt},
-			{Role: "user", Content: input},
-		},
+	toolDefs := l.toolDefinitions()
+
+	messages := []Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: input},
+	}
+
+	for iteration := 0; ; iteration++ {
+		if iteration >= maxToolIterations {
+			return "", fmt.Errorf("exceeded %d tool-call iterations without a final answer", maxToolIterations)
+		}
+
+		message, err := l.chatCompletion(Request{
+			Model:    l.model,
+			Messages: messages,
+			Tools:    toolDefs,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		if len(message.ToolCalls) == 0 {
+			log.Debug("OpenAI LLM returned: %q", message.Content)
+			return message.Content, nil
+		}
+
+		log.Debug("Model requested %d tool call(s)", len(message.ToolCalls))
+		messages = append(messages, message)
+		for _, call := range message.ToolCalls {
+			result := l.callTool(call.Function.Name, call.Function.Arguments)
+			messages = append(messages, Message{
+				Role:       "tool",
+				ToolCallID: call.ID,
+				Content:    result,
+			})
+		}
 	}
+}
 
+// chatCompletion sends a single Chat Completions request and returns the
+// assistant message from the first choice.
+func (l *LLM) chatCompletion(reqBody Request) (Message, error) {
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		log.Debug("Failed to marshal request: %v", err)
-		return "", fmt.Errorf("failed to marshal request: %v", err)
+		return Message{}, fmt.Errorf("failed to marshal request: %v", err)
 	}
 
 	req, err := http.NewRequest("POST", l.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
 		log.Debug("Failed to create request: %v", err)
-		return "", fmt.Errorf("failed to create request: %v", err)
+		return Message{}, fmt.Errorf("failed to create request: %v", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -121,34 +245,32 @@ func (l *LLM) Process(prompt, input string) (string, error) {
 	resp, err := l.httpClient.Do(req)
 	if err != nil {
 		log.Debug("Failed to call OpenAI API: %v", err)
-		return "", fmt.Errorf("failed to call OpenAI API: %v", err)
+		return Message{}, fmt.Errorf("failed to call OpenAI API: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.Debug("Failed to read response body: %v", err)
-		return "", fmt.Errorf("failed to read response body: %v", err)
+		return Message{}, fmt.Errorf("failed to read response body: %v", err)
 	}
 	log.Debug("Response from OpenAI API: %s", string(body))
 
 	if resp.StatusCode != http.StatusOK {
 		log.Debug("OpenAI API returned status %d: %s", resp.StatusCode, string(body))
-		return "", fmt.Errorf("OpenAI API returned status %d: %s", resp.StatusCode, string(body))
+		return Message{}, fmt.Errorf("OpenAI API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	var openAIResp Response
 	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&openAIResp); err != nil {
 		log.Debug("Failed to decode OpenAI response: %v", err)
-		return "", fmt.Errorf("failed to decode OpenAI response: %v", err)
+		return Message{}, fmt.Errorf("failed to decode OpenAI response: %v", err)
 	}
 
 	if len(openAIResp.Choices) == 0 {
 		log.Debug("No response from OpenAI API")
-		return "", fmt.Errorf("no response from OpenAI API")
+		return Message{}, fmt.Errorf("no response from OpenAI API")
 	}
 
-	result := openAIResp.Choices[0].Message.Content
-	log.Debug("OpenAI LLM returned: %q", result)
-	return result, nil
+	return openAIResp.Choices[0].Message, nil
 }
@@ -0,0 +1,208 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gendo/pkg/tools"
+)
+
+// echoTool is a tools.Tool stub that records the arguments it was called
+// with and returns a fixed result, for tests that need to observe what the
+// tool-calling loop passed through without exercising a real tool.
+type echoTool struct {
+	calls  []string
+	result string
+}
+
+func (e *echoTool) Process(input string) (string, error) {
+	e.calls = append(e.calls, input)
+	return e.result, nil
+}
+
+func (e *echoTool) Schema() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+
+func (e *echoTool) Description() string {
+	return "echoes back a fixed result"
+}
+
+// newTestLLM starts an httptest.Server that responds to POST /chat/completions
+// with the given responses in order (one per call), and returns an *LLM
+// pointed at it.
+func newTestLLM(t *testing.T, toolRegistry tools.Registry, responses ...Response) (*LLM, *int) {
+	t.Helper()
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls >= len(responses) {
+			t.Fatalf("unexpected extra request %d, only %d responses configured", calls+1, len(responses))
+		}
+		resp := responses[calls]
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("failed to encode fake response: %v", err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	llm := New("test-key", "test-model", toolRegistry)
+	llm.baseURL = srv.URL
+	return llm, &calls
+}
+
+func TestProcessNoToolCallsReturnsContent(t *testing.T) {
+	llm, calls := newTestLLM(t, nil, Response{
+		Choices: []struct {
+			Message Message `json:"message"`
+		}{
+			{Message: Message{Role: "assistant", Content: "the answer"}},
+		},
+	})
+
+	got, err := llm.Process("system prompt", "user input")
+	if err != nil {
+		t.Fatalf("Process() error: %v", err)
+	}
+	if got != "the answer" {
+		t.Errorf("Process() = %q, want %q", got, "the answer")
+	}
+	if *calls != 1 {
+		t.Errorf("made %d requests, want 1", *calls)
+	}
+}
+
+func TestProcessMultiCallAccumulatesToolResults(t *testing.T) {
+	tool := &echoTool{result: "tool output"}
+	toolRegistry := tools.NewRegistry()
+	toolRegistry.Register("echo", tool)
+
+	llm, calls := newTestLLM(t, toolRegistry,
+		Response{Choices: []struct {
+			Message Message `json:"message"`
+		}{
+			{Message: Message{
+				Role: "assistant",
+				ToolCalls: []ToolCall{
+					{ID: "call-1", Type: "function", Function: struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					}{Name: "echo", Arguments: `{"a":1}`}},
+					{ID: "call-2", Type: "function", Function: struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					}{Name: "echo", Arguments: `{"a":2}`}},
+				},
+			}},
+		}},
+		Response{Choices: []struct {
+			Message Message `json:"message"`
+		}{
+			{Message: Message{Role: "assistant", Content: "final answer"}},
+		}},
+	)
+
+	got, err := llm.Process("system prompt", "user input")
+	if err != nil {
+		t.Fatalf("Process() error: %v", err)
+	}
+	if got != "final answer" {
+		t.Errorf("Process() = %q, want %q", got, "final answer")
+	}
+	if *calls != 2 {
+		t.Errorf("made %d requests, want 2", *calls)
+	}
+	if len(tool.calls) != 2 || tool.calls[0] != `{"a":1}` || tool.calls[1] != `{"a":2}` {
+		t.Errorf("tool was called with %v, want both tool calls' arguments in order", tool.calls)
+	}
+}
+
+func TestProcessUnknownToolReturnsErrorMessageToModel(t *testing.T) {
+	toolRegistry := tools.NewRegistry()
+
+	var gotToolContent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if len(req.Messages) > 0 && req.Messages[len(req.Messages)-1].Role == "tool" {
+			gotToolContent = req.Messages[len(req.Messages)-1].Content
+			json.NewEncoder(w).Encode(Response{Choices: []struct {
+				Message Message `json:"message"`
+			}{{Message: Message{Role: "assistant", Content: "done"}}}})
+			return
+		}
+		json.NewEncoder(w).Encode(Response{Choices: []struct {
+			Message Message `json:"message"`
+		}{{Message: Message{
+			Role: "assistant",
+			ToolCalls: []ToolCall{
+				{ID: "call-1", Type: "function", Function: struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				}{Name: "does-not-exist", Arguments: `{}`}},
+			},
+		}}}})
+	}))
+	t.Cleanup(srv.Close)
+
+	llm := New("test-key", "test-model", toolRegistry)
+	llm.baseURL = srv.URL
+
+	got, err := llm.Process("system prompt", "user input")
+	if err != nil {
+		t.Fatalf("Process() error: %v", err)
+	}
+	if got != "done" {
+		t.Errorf("Process() = %q, want %q", got, "done")
+	}
+	if gotToolContent != `error: unknown tool "does-not-exist"` {
+		t.Errorf("tool message content = %q, want unknown-tool error", gotToolContent)
+	}
+}
+
+func TestProcessExceedsMaxToolIterations(t *testing.T) {
+	toolRegistry := tools.NewRegistry()
+	toolRegistry.Register("echo", &echoTool{result: "ok"})
+
+	loopForever := Response{Choices: []struct {
+		Message Message `json:"message"`
+	}{{Message: Message{
+		Role: "assistant",
+		ToolCalls: []ToolCall{
+			{ID: "call", Type: "function", Function: struct {
+				Name      string `json:"name"`
+				Arguments string `json:"arguments"`
+			}{Name: "echo", Arguments: "{}"}},
+		},
+	}}}}
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(loopForever)
+	}))
+	t.Cleanup(srv.Close)
+
+	llm := New("test-key", "test-model", toolRegistry)
+	llm.baseURL = srv.URL
+
+	_, err := llm.Process("system prompt", "user input")
+	if err == nil {
+		t.Fatal("Process() error = nil, want exceeded-iterations error")
+	}
+	wantErr := fmt.Sprintf("exceeded %d tool-call iterations without a final answer", maxToolIterations)
+	if err.Error() != wantErr {
+		t.Errorf("Process() error = %q, want %q", err.Error(), wantErr)
+	}
+	if requests != maxToolIterations {
+		t.Errorf("made %d requests, want %d", requests, maxToolIterations)
+	}
+}
@@ -1,59 +1,249 @@
-// Package log provides logging functionality for the Gendo tool.
-// It supports different log levels (Debug, Info, Error) and includes
-// caller context information in log messages. The package allows
-// configuration of verbosity and output destination.
+// Package log provides structured, leveled logging for Gendo. A Logger
+// pairs a pluggable Handler (text or JSON) with a level and a set of
+// key/value fields attached via With, so a script run can tag every line it
+// emits with context like a pipeline ID or the node currently processing.
+// The package-level Debug/Info/Warn/Error/SetVerbose/SetOutput functions are
+// thin wrappers over Default(), kept for callers that don't need a
+// dedicated Logger.
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 )
 
-var (
-	verbose bool
-	output  io.Writer = os.Stderr
+// Level orders log severity from least to most severe.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
 )
 
-// SetVerbose enables or disables verbose logging
-func SetVerbose(v bool) {
-	verbose = v
+// String returns the lowercase name of the level, e.g. "debug".
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
 }
 
-// SetOutput sets the output writer for logging
-func SetOutput(w io.Writer) {
-	output = w
+// Field is a single key/value pair attached to a Logger via With.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Entry is a single log line passed to a Handler.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Caller  string
+	Fields  []Field
+}
+
+// Handler renders and writes a log Entry. TextHandler and JSONHandler are
+// the two built-in implementations.
+type Handler interface {
+	Handle(Entry)
+}
+
+// TextHandler writes entries in the package's original human-readable
+// format: "LEVEL [file:line]: message key=value ...".
+type TextHandler struct {
+	w io.Writer
+}
+
+// NewTextHandler creates a TextHandler writing to w.
+func NewTextHandler(w io.Writer) *TextHandler {
+	return &TextHandler{w: w}
+}
+
+// Handle implements Handler.
+func (h *TextHandler) Handle(e Entry) {
+	fmt.Fprintf(h.w, "%s [%s]: %s", levelTag(e.Level), e.Caller, e.Message)
+	for _, f := range e.Fields {
+		fmt.Fprintf(h.w, " %s=%v", f.Key, f.Value)
+	}
+	fmt.Fprintln(h.w)
+}
+
+func levelTag(l Level) string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// JSONHandler writes each entry as a single JSON object per line, for
+// machine consumers (log aggregators, structured log search).
+type JSONHandler struct {
+	w io.Writer
+}
+
+// NewJSONHandler creates a JSONHandler writing to w.
+func NewJSONHandler(w io.Writer) *JSONHandler {
+	return &JSONHandler{w: w}
+}
+
+// Handle implements Handler.
+func (h *JSONHandler) Handle(e Entry) {
+	record := make(map[string]interface{}, 4+len(e.Fields))
+	record["time"] = e.Time.Format(time.RFC3339Nano)
+	record["level"] = e.Level.String()
+	record["caller"] = e.Caller
+	record["msg"] = e.Message
+	for _, f := range e.Fields {
+		record[f.Key] = f.Value
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		fmt.Fprintf(h.w, `{"level":"error","msg":"failed to marshal log entry: %v"}`+"\n", err)
+		return
+	}
+	h.w.Write(append(data, '\n'))
 }
 
-// getCallerContext returns the file name and line number of the caller
-func getCallerContext() string {
-	_, file, line, ok := runtime.Caller(2) // Skip 2 frames to get the actual caller
+// Logger emits entries at or above its level to its Handler, tagging each
+// with any fields attached via With.
+type Logger struct {
+	handler Handler
+	level   Level
+	fields  []Field
+}
+
+// New creates a Logger writing to handler at LevelInfo (Debug lines are
+// suppressed until SetLevel(LevelDebug) is called).
+func New(handler Handler) *Logger {
+	return &Logger{handler: handler, level: LevelInfo}
+}
+
+// SetLevel changes the minimum level this Logger emits.
+func (l *Logger) SetLevel(level Level) {
+	l.level = level
+}
+
+// SetHandler changes where this Logger's entries are written.
+func (l *Logger) SetHandler(handler Handler) {
+	l.handler = handler
+}
+
+// With returns a child Logger that additionally tags every line with the
+// given key/value pairs, e.g. logger.With("node", id, "tool", name). An odd
+// trailing argument, or a key that isn't a string, is dropped.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	fields := make([]Field, len(l.fields), len(l.fields)+len(kv)/2)
+	copy(fields, l.fields)
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			continue
+		}
+		fields = append(fields, Field{Key: key, Value: kv[i+1]})
+	}
+	return &Logger{handler: l.handler, level: l.level, fields: fields}
+}
+
+func (l *Logger) log(level Level, format string, args ...interface{}) {
+	if level < l.level {
+		return
+	}
+	l.handler.Handle(Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+		Caller:  callerContext(),
+		Fields:  l.fields,
+	})
+}
+
+// Debug logs a message at LevelDebug.
+func (l *Logger) Debug(format string, args ...interface{}) { l.log(LevelDebug, format, args...) }
+
+// Info logs a message at LevelInfo.
+func (l *Logger) Info(format string, args ...interface{}) { l.log(LevelInfo, format, args...) }
+
+// Warn logs a message at LevelWarn.
+func (l *Logger) Warn(format string, args ...interface{}) { l.log(LevelWarn, format, args...) }
+
+// Error logs a message at LevelError.
+func (l *Logger) Error(format string, args ...interface{}) { l.log(LevelError, format, args...) }
+
+// callerContext returns the file name and line number of the original
+// caller of a package-level or Logger log method. It must be called exactly
+// one frame below that method (see log's and the package-level wrappers'
+// call sites) for the skip count below to land on the right frame.
+func callerContext() string {
+	_, file, line, ok := runtime.Caller(3)
 	if !ok {
 		return "unknown:0"
 	}
-	// Get just the file name without the full path
-	file = filepath.Base(file)
-	return fmt.Sprintf("%s:%d", file, line)
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+}
+
+var defaultLogger = New(NewTextHandler(os.Stderr))
+
+// Default returns the package-wide Logger used by the package-level
+// Debug/Info/Warn/Error/With/SetVerbose/SetOutput functions, for callers
+// that want to build on it (e.g. logger := log.Default().With("pipeline", id))
+// rather than construct their own.
+func Default() *Logger {
+	return defaultLogger
 }
 
-// Debug logs a debug message if verbose mode is enabled
-func Debug(format string, args ...interface{}) {
-	if verbose {
-		context := getCallerContext()
-		fmt.Fprintf(output, "DEBUG [%s]: "+format+"\n", append([]interface{}{context}, args...)...)
+// SetVerbose enables or disables debug-level logging on the default logger.
+func SetVerbose(v bool) {
+	if v {
+		defaultLogger.SetLevel(LevelDebug)
+	} else {
+		defaultLogger.SetLevel(LevelInfo)
 	}
 }
 
-// Info logs an info message
-func Info(format string, args ...interface{}) {
-	context := getCallerContext()
-	fmt.Fprintf(output, "INFO [%s]: "+format+"\n", append([]interface{}{context}, args...)...)
+// SetOutput sets the output writer for the default logger's handler.
+func SetOutput(w io.Writer) {
+	defaultLogger.SetHandler(NewTextHandler(w))
 }
 
-// Error logs an error message
-func Error(format string, args ...interface{}) {
-	context := getCallerContext()
-	fmt.Fprintf(output, "ERROR [%s]: "+format+"\n", append([]interface{}{context}, args...)...)
+// With returns a child of the default logger tagged with the given
+// key/value fields; see Logger.With.
+func With(kv ...interface{}) *Logger {
+	return defaultLogger.With(kv...)
 }
+
+// Debug logs a debug message on the default logger.
+func Debug(format string, args ...interface{}) { defaultLogger.log(LevelDebug, format, args...) }
+
+// Info logs an info message on the default logger.
+func Info(format string, args ...interface{}) { defaultLogger.log(LevelInfo, format, args...) }
+
+// Warn logs a warning message on the default logger.
+func Warn(format string, args ...interface{}) { defaultLogger.log(LevelWarn, format, args...) }
+
+// Error logs an error message on the default logger.
+func Error(format string, args ...interface{}) { defaultLogger.log(LevelError, format, args...) }
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoggerLevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewTextHandler(&buf))
+
+	logger.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("Debug() at default LevelInfo wrote %q, want nothing", buf.String())
+	}
+
+	logger.Info("shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Errorf("Info() output = %q, want it to contain %q", buf.String(), "shown")
+	}
+
+	buf.Reset()
+	logger.SetLevel(LevelDebug)
+	logger.Debug("now shown")
+	if !strings.Contains(buf.String(), "now shown") {
+		t.Errorf("Debug() after SetLevel(LevelDebug) output = %q, want it to contain %q", buf.String(), "now shown")
+	}
+}
+
+func TestLoggerWithFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewTextHandler(&buf))
+
+	logger.With("node", 2, "tool", "math").Info("processing")
+
+	got := buf.String()
+	for _, want := range []string{"processing", "node=2", "tool=math"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestLoggerWithIsImmutable(t *testing.T) {
+	var buf bytes.Buffer
+	base := New(NewTextHandler(&buf))
+	tagged := base.With("pipeline", "a")
+
+	buf.Reset()
+	base.Info("untagged")
+	if strings.Contains(buf.String(), "pipeline=") {
+		t.Errorf("base logger output = %q, should not have inherited tagged's fields", buf.String())
+	}
+
+	buf.Reset()
+	tagged.Info("tagged")
+	if !strings.Contains(buf.String(), "pipeline=a") {
+		t.Errorf("tagged logger output = %q, want it to contain %q", buf.String(), "pipeline=a")
+	}
+}
+
+func TestJSONHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+
+	logger.With("node", 3).Info("hello")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("output %q is not valid JSON: %v", buf.String(), err)
+	}
+	if record["msg"] != "hello" {
+		t.Errorf("record[\"msg\"] = %v, want %q", record["msg"], "hello")
+	}
+	if record["level"] != "info" {
+		t.Errorf("record[\"level\"] = %v, want %q", record["level"], "info")
+	}
+	if record["node"] != float64(3) {
+		t.Errorf("record[\"node\"] = %v, want %v", record["node"], 3)
+	}
+}
+
+func TestPackageLevelWrappersUseDefaultLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+	t.Cleanup(func() { SetVerbose(false) })
+
+	SetVerbose(false)
+	Debug("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("Debug() with verbose disabled wrote %q, want nothing", buf.String())
+	}
+
+	SetVerbose(true)
+	Debug("shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Errorf("Debug() with verbose enabled output = %q, want it to contain %q", buf.String(), "shown")
+	}
+}
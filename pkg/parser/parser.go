@@ -3,6 +3,8 @@ package parser
 import (
 	"strconv"
 	"strings"
+
+	"gendo/pkg/log"
 )
 
 // NodeType represents the type of a node
@@ -13,17 +15,24 @@ const (
 	NodeTypeIn      NodeType = "in"
 	NodeTypeOut     NodeType = "out"
 	NodeTypeErr     NodeType = "err"
+	NodeTypeHTTP    NodeType = "http"
 	NodeTypeDefault NodeType = ""
 )
 
 // NodeDefinition represents a parsed node from a script line
 type NodeDefinition struct {
-	ID     int
-	RefIDs []int  // Reference IDs this node can call
-	Prompt string // Optional prompt text
-	IsTool bool   // Whether this is a tool node
-	Tool   string // Tool name if IsTool is true
-	Type   NodeType
+	ID           int
+	RefIDs       []int    // Reference IDs this node can call
+	Prompt       string   // Optional prompt text
+	IsTool       bool     // Whether this is a tool node
+	Tool         string   // Tool name if IsTool is true
+	IsHTTP       bool     // Whether this node is bound to an HTTP endpoint
+	HTTPMethod   string   // HTTP method if IsHTTP is true, e.g. "GET"
+	HTTPPath     string   // HTTP path template if IsHTTP is true, e.g. "/users/{user}/summary"
+	LLM          string   // Name of the LLM registry entry to route this node's prompt to, e.g. "falcon" or an "openai:gpt-4o" composite key; empty selects the default "openai" entry
+	BackendChain []string // Ordered registry names to retry on error before giving up, from a "backend: name1,name2 ..." binding; empty disables the fallback chain
+	Stream       bool     // Whether this node opted into "stream " token-level output
+	Type         NodeType
 }
 
 // RouteDefinition represents a routing between nodes with optional error handling
@@ -34,8 +43,14 @@ type RouteDefinition struct {
 	Input     string // Input text
 }
 
-// ParseLine parses a single line from a Gendo script
-func ParseLine(line string) (interface{}, bool) {
+// ParseLine parses a single line from a Gendo script, logging through logger
+// (a nil logger falls back to log.Default()) why a line was rejected, so a
+// script author can tell a typo from an intentional comment or blank line.
+func ParseLine(line string, logger *log.Logger) (interface{}, bool) {
+	if logger == nil {
+		logger = log.Default()
+	}
+
 	// Trim spaces and skip empty lines or comments
 	line = strings.TrimSpace(line)
 	if line == "" || strings.HasPrefix(line, "#") {
@@ -44,21 +59,22 @@ func ParseLine(line string) (interface{}, bool) {
 
 	// Check if this is a node definition (contains : but not <)
 	if strings.Contains(line, ":") && !strings.Contains(line, "<") {
-		return parseNodeDefinition(line)
+		return parseNodeDefinition(line, logger)
 	}
 
 	// Otherwise it's a routing line
-	return parseRouting(line)
+	return parseRouting(line, logger)
 }
 
 // parseNodeDefinition parses a node definition line
-func parseNodeDefinition(line string) (*NodeDefinition, bool) {
+func parseNodeDefinition(line string, logger *log.Logger) (*NodeDefinition, bool) {
 	// Remove any leading/trailing whitespace
 	line = strings.TrimSpace(line)
 
 	// Split by colon
 	parts := strings.SplitN(line, ":", 2)
 	if len(parts) != 2 {
+		logger.Debug("Rejecting node definition %q: missing ':'", line)
 		return nil, false
 	}
 
@@ -66,6 +82,7 @@ func parseNodeDefinition(line string) (*NodeDefinition, bool) {
 	idStr := strings.TrimSpace(parts[0])
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
+		logger.Debug("Rejecting node definition %q: invalid node ID %q: %v", line, idStr, err)
 		return nil, false
 	}
 
@@ -74,8 +91,20 @@ func parseNodeDefinition(line string) (*NodeDefinition, bool) {
 	var refIDs []int
 	var prompt string
 	var tool string
+	var httpMethod, httpPath string
+	var llmName string
+	var backendChain []string
 	var nodeType NodeType
 
+	// A leading "stream " opts this node into token-level output (see
+	// gendo.Node.Stream) and is stripped before the rest of the definition
+	// is parsed as usual, so it composes with "tool", "llm", and plain
+	// prompt nodes, e.g. "3: stream tool math" or "2: stream llm falcon ...".
+	stream := strings.HasPrefix(rest, "stream ")
+	if stream {
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, "stream"))
+	}
+
 	// Check for special node types first
 	if rest == "in" {
 		nodeType = NodeTypeIn
@@ -83,6 +112,28 @@ func parseNodeDefinition(line string) (*NodeDefinition, bool) {
 		nodeType = NodeTypeOut
 	} else if rest == "err" {
 		nodeType = NodeTypeErr
+	} else if strings.HasPrefix(rest, "http ") {
+		nodeType = NodeTypeHTTP
+		httpMethod, httpPath = parseHTTPBinding(strings.TrimSpace(strings.TrimPrefix(rest, "http")))
+	} else if strings.HasPrefix(rest, "llm ") {
+		// "llm <name> <prompt>" routes this node's prompt to the named
+		// backend in the LLM registry instead of the default "openai" entry.
+		llmName, prompt = parseLLMBinding(strings.TrimSpace(strings.TrimPrefix(rest, "llm")))
+	} else if strings.HasPrefix(rest, "backend:") {
+		// "backend: name1,name2 <prompt>" tries each registry entry in
+		// order, falling through to the next on error, see
+		// gendo.Node.BackendChain.
+		backendChain, prompt = parseBackendChain(strings.TrimPrefix(rest, "backend:"))
+	} else if backend, model, bindingPrompt, ok := parseBackendModelBinding(rest); ok {
+		// "<backend>:<model> <prompt>" routes this node's prompt straight to
+		// that provider/model pair via a composite "backend:model" registry
+		// key (see gendo.NewRegistries), bypassing the default "openai"
+		// entry and the named "llm <name> ..." binding above.
+		llmName = backend
+		if model != "" {
+			llmName = backend + ":" + model
+		}
+		prompt = bindingPrompt
 	} else {
 		// Parse tool or prompt
 		if strings.HasPrefix(rest, "tool") {
@@ -95,7 +146,11 @@ func parseNodeDefinition(line string) (*NodeDefinition, bool) {
 				if refID, err := strconv.Atoi(ref); err == nil {
 					refIDs = append(refIDs, refID)
 				} else {
-					prompt = rest
+					// A "N : : prompt" double-colon definition leaves rest
+					// with a leading ": " (the empty "tool or ref" segment
+					// between the two colons); strip it so it doesn't end
+					// up as part of the prompt text itself.
+					prompt = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ":"))
 					break
 				}
 			}
@@ -103,16 +158,81 @@ func parseNodeDefinition(line string) (*NodeDefinition, bool) {
 	}
 
 	return &NodeDefinition{
-		ID:     id,
-		RefIDs: refIDs,
-		Prompt: prompt,
-		Tool:   tool,
-		Type:   nodeType,
+		ID:           id,
+		RefIDs:       refIDs,
+		Prompt:       prompt,
+		IsTool:       nodeType == NodeTypeTool,
+		Tool:         tool,
+		IsHTTP:       nodeType == NodeTypeHTTP,
+		HTTPMethod:   httpMethod,
+		HTTPPath:     httpPath,
+		LLM:          llmName,
+		BackendChain: backendChain,
+		Stream:       stream,
+		Type:         nodeType,
 	}, true
 }
 
+// parseBackendModelBinding detects a leading "<backend>:<model> <prompt>"
+// token, e.g. "openai:gpt-4o summarize this", splitting it into the
+// provider name, the model override, and the remaining prompt text. ok is
+// false when rest's first token has no ":", or the ":" sits at the very
+// start or end of it, so an ordinary prompt word like "Q:" or the malformed
+// leading ": " left by the double-colon prompt syntax is never misread as a
+// binding.
+func parseBackendModelBinding(rest string) (backend, model, prompt string, ok bool) {
+	first, remainder, hasPrompt := strings.Cut(rest, " ")
+	idx := strings.Index(first, ":")
+	if idx <= 0 || idx == len(first)-1 {
+		return "", "", "", false
+	}
+
+	backend = first[:idx]
+	model = first[idx+1:]
+	if hasPrompt {
+		prompt = strings.TrimSpace(remainder)
+	}
+	return backend, model, prompt, true
+}
+
+// parseBackendChain splits the remainder of a "backend: name1,name2 ..."
+// node definition into the ordered registry names to retry on error and the
+// prompt to send to whichever one succeeds first.
+func parseBackendChain(rest string) (chain []string, prompt string) {
+	namesPart, promptPart, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	for _, name := range strings.Split(namesPart, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			chain = append(chain, name)
+		}
+	}
+	prompt = strings.TrimSpace(promptPart)
+	return chain, prompt
+}
+
+// parseLLMBinding splits the remainder of an "llm NAME prompt..." node
+// definition into the backend name to route to and the prompt text.
+func parseLLMBinding(rest string) (name, prompt string) {
+	parts := strings.SplitN(rest, " ", 2)
+	name = parts[0]
+	if len(parts) > 1 {
+		prompt = strings.TrimSpace(parts[1])
+	}
+	return name, prompt
+}
+
+// parseHTTPBinding splits the remainder of an "http METHOD /path" node
+// definition into its method and path template.
+func parseHTTPBinding(rest string) (method, path string) {
+	parts := strings.SplitN(rest, " ", 2)
+	method = strings.ToUpper(parts[0])
+	if len(parts) > 1 {
+		path = strings.TrimSpace(parts[1])
+	}
+	return method, path
+}
+
 // parseRouting parses a routing line: [errorDest !] [dest <] src input text
-func parseRouting(line string) (*RouteDefinition, bool) {
+func parseRouting(line string, logger *log.Logger) (*RouteDefinition, bool) {
 	route := &RouteDefinition{}
 
 	// Check for error destination
@@ -141,12 +261,14 @@ func parseRouting(line string) (*RouteDefinition, bool) {
 	// The remaining parts are source and input
 	parts := strings.SplitN(line, " ", 2)
 	if len(parts) < 1 {
+		logger.Debug("Rejecting routing line %q: no source node", line)
 		return nil, false
 	}
 
 	// Parse source ID
 	srcID, err := strconv.Atoi(strings.TrimSpace(parts[0]))
 	if err != nil {
+		logger.Debug("Rejecting routing line %q: invalid source node ID %q: %v", line, parts[0], err)
 		return nil, false
 	}
 	route.Source = srcID
@@ -56,6 +56,38 @@ func TestParseLine(t *testing.T) {
 			},
 			wantOk: true,
 		},
+		{
+			name:  "LLM binding",
+			input: "2 : llm falcon Summarize this conversation",
+			want: &NodeDefinition{
+				ID:     2,
+				LLM:    "falcon",
+				Prompt: "Summarize this conversation",
+			},
+			wantOk: true,
+		},
+		{
+			name:  "Streaming tool definition",
+			input: "3 : stream tool write",
+			want: &NodeDefinition{
+				ID:     3,
+				IsTool: true,
+				Tool:   "write",
+				Stream: true,
+			},
+			wantOk: true,
+		},
+		{
+			name:  "HTTP binding",
+			input: "2 : http GET /users/{user}/summary",
+			want: &NodeDefinition{
+				ID:         2,
+				IsHTTP:     true,
+				HTTPMethod: "GET",
+				HTTPPath:   "/users/{user}/summary",
+			},
+			wantOk: true,
+		},
 		{
 			name:  "Simple routing",
 			input: "3 < 0",
@@ -98,6 +130,26 @@ func TestParseLine(t *testing.T) {
 			want:   nil,
 			wantOk: false,
 		},
+		{
+			name:  "Backend and model binding",
+			input: "2 : openai:gpt-4o Summarize this conversation",
+			want: &NodeDefinition{
+				ID:     2,
+				LLM:    "openai:gpt-4o",
+				Prompt: "Summarize this conversation",
+			},
+			wantOk: true,
+		},
+		{
+			name:  "Backend fallback chain",
+			input: "2 : backend: openai,ollama Summarize this conversation",
+			want: &NodeDefinition{
+				ID:           2,
+				BackendChain: []string{"openai", "ollama"},
+				Prompt:       "Summarize this conversation",
+			},
+			wantOk: true,
+		},
 		{
 			name:  "Node with prompt containing colons",
 			input: "1 : : Format result: add prefix and suffix: done",
@@ -120,7 +172,7 @@ func TestParseLine(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, gotOk := ParseLine(tt.input)
+			got, gotOk := ParseLine(tt.input, nil)
 			if gotOk != tt.wantOk {
 				t.Errorf("ParseLine() ok = %v, want %v", gotOk, tt.wantOk)
 				return
@@ -151,6 +203,24 @@ func TestParseLine(t *testing.T) {
 				if got.Prompt != want.Prompt {
 					t.Errorf("ParseLine() Prompt = %v, want %v", got.Prompt, want.Prompt)
 				}
+				if got.IsHTTP != want.IsHTTP {
+					t.Errorf("ParseLine() IsHTTP = %v, want %v", got.IsHTTP, want.IsHTTP)
+				}
+				if got.HTTPMethod != want.HTTPMethod {
+					t.Errorf("ParseLine() HTTPMethod = %v, want %v", got.HTTPMethod, want.HTTPMethod)
+				}
+				if got.HTTPPath != want.HTTPPath {
+					t.Errorf("ParseLine() HTTPPath = %v, want %v", got.HTTPPath, want.HTTPPath)
+				}
+				if got.LLM != want.LLM {
+					t.Errorf("ParseLine() LLM = %v, want %v", got.LLM, want.LLM)
+				}
+				if !reflect.DeepEqual(got.BackendChain, want.BackendChain) {
+					t.Errorf("ParseLine() BackendChain = %v, want %v", got.BackendChain, want.BackendChain)
+				}
+				if got.Stream != want.Stream {
+					t.Errorf("ParseLine() Stream = %v, want %v", got.Stream, want.Stream)
+				}
 			case *RouteDefinition:
 				got, ok := got.(*RouteDefinition)
 				if !ok {
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpKind identifies what a single compiled path template segment matches.
+type OpKind int
+
+const (
+	// OpLiteral matches a fixed path segment verbatim.
+	OpLiteral OpKind = iota
+	// OpCapture matches exactly one path segment and binds it to a name.
+	OpCapture
+	// OpCaptureRest matches one or more remaining path segments (joined
+	// with "/") and binds them to a name. Only valid as the final op.
+	OpCaptureRest
+)
+
+// Op is a single compiled segment of a path template, as produced by
+// ParseTemplate and consumed by Match.
+type Op struct {
+	Kind  OpKind
+	Value string // literal text for OpLiteral, variable name for the others
+}
+
+// ParseTemplate compiles a path template such as "/users/{user}/summary" or
+// "/files/{path=**}" into an ordered list of Ops. Templates use gRPC-gateway
+// style captures: "{name}" captures one segment, "{name=**}" captures all
+// remaining segments and must be the last element of the template.
+func ParseTemplate(template string) ([]Op, error) {
+	template = strings.Trim(template, "/")
+	if template == "" {
+		return nil, nil
+	}
+
+	segments := strings.Split(template, "/")
+	ops := make([]Op, 0, len(segments))
+
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			ops = append(ops, Op{Kind: OpLiteral, Value: seg})
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(seg, "{"), "}")
+		if rest, ok := strings.CutSuffix(name, "=**"); ok {
+			if i != len(segments)-1 {
+				return nil, fmt.Errorf("capture-rest segment %q must be the last segment of the template", seg)
+			}
+			ops = append(ops, Op{Kind: OpCaptureRest, Value: rest})
+			continue
+		}
+
+		ops = append(ops, Op{Kind: OpCapture, Value: name})
+	}
+
+	return ops, nil
+}
+
+// Match walks the segments of path against ops and, on success, returns the
+// captured variables.
+func Match(ops []Op, path string) (map[string]string, bool) {
+	path = strings.Trim(path, "/")
+	var segments []string
+	if path != "" {
+		segments = strings.Split(path, "/")
+	}
+
+	vars := make(map[string]string)
+
+	for i, op := range ops {
+		switch op.Kind {
+		case OpCaptureRest:
+			if i >= len(segments) {
+				return nil, false
+			}
+			vars[op.Value] = strings.Join(segments[i:], "/")
+			return vars, true
+		default:
+			if i >= len(segments) {
+				return nil, false
+			}
+			if op.Kind == OpLiteral && segments[i] != op.Value {
+				return nil, false
+			}
+			if op.Kind == OpCapture {
+				vars[op.Value] = segments[i]
+			}
+		}
+	}
+
+	if len(segments) != len(ops) {
+		return nil, false
+	}
+
+	return vars, true
+}
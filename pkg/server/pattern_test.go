@@ -0,0 +1,125 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Op
+		wantErr bool
+	}{
+		{
+			name:  "literal only",
+			input: "/users",
+			want:  []Op{{Kind: OpLiteral, Value: "users"}},
+		},
+		{
+			name:  "literal with capture",
+			input: "/users/{user}/summary",
+			want: []Op{
+				{Kind: OpLiteral, Value: "users"},
+				{Kind: OpCapture, Value: "user"},
+				{Kind: OpLiteral, Value: "summary"},
+			},
+		},
+		{
+			name:  "capture rest",
+			input: "/files/{path=**}",
+			want: []Op{
+				{Kind: OpLiteral, Value: "files"},
+				{Kind: OpCaptureRest, Value: "path"},
+			},
+		},
+		{
+			name:    "capture rest not last",
+			input:   "/files/{path=**}/extra",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTemplate(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTemplate(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTemplate(%q) unexpected error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTemplate(%q) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		path     string
+		wantVars map[string]string
+		wantOk   bool
+	}{
+		{
+			name:     "exact literal",
+			template: "/users",
+			path:     "/users",
+			wantVars: map[string]string{},
+			wantOk:   true,
+		},
+		{
+			name:     "captured segment",
+			template: "/users/{user}/summary",
+			path:     "/users/alice/summary",
+			wantVars: map[string]string{"user": "alice"},
+			wantOk:   true,
+		},
+		{
+			name:     "literal mismatch",
+			template: "/users/{user}/summary",
+			path:     "/users/alice/profile",
+			wantOk:   false,
+		},
+		{
+			name:     "too few segments",
+			template: "/users/{user}/summary",
+			path:     "/users/alice",
+			wantOk:   false,
+		},
+		{
+			name:     "capture rest consumes remainder",
+			template: "/files/{path=**}",
+			path:     "/files/a/b/c.txt",
+			wantVars: map[string]string{"path": "a/b/c.txt"},
+			wantOk:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := ParseTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("ParseTemplate(%q) error: %v", tt.template, err)
+			}
+
+			vars, ok := Match(ops, tt.path)
+			if ok != tt.wantOk {
+				t.Fatalf("Match(%q) ok = %v, want %v", tt.path, ok, tt.wantOk)
+			}
+			if !tt.wantOk {
+				return
+			}
+			if !reflect.DeepEqual(vars, tt.wantVars) {
+				t.Errorf("Match(%q) vars = %v, want %v", tt.path, vars, tt.wantVars)
+			}
+		})
+	}
+}
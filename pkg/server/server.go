@@ -0,0 +1,177 @@
+// Package server exposes a running Gendo script as an HTTP service. Each
+// node ID is addressable directly at POST /nodes/{id}, and a script can
+// additionally bind a node to a URL template with an "http" node directive,
+// e.g. "2 : http GET /users/{user}/summary", in which case path captures
+// are passed to the node as JSON input.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gendo/internal/gendo"
+	"gendo/pkg/llm"
+	"gendo/pkg/log"
+	"gendo/pkg/tools"
+)
+
+// route is a single compiled HTTP binding for a node.
+type route struct {
+	method string
+	ops    []Op
+	node   gendo.Node
+}
+
+// Server implements http.Handler over a loaded Gendo script's nodes.
+type Server struct {
+	nodes        map[int]gendo.Node
+	toolRegistry tools.Registry
+	llmRegistry  llm.Registry
+	routes       []route
+	logger       *log.Logger
+}
+
+// New loads filename and builds a Server over its nodes. model and backend
+// select the primary LLM implementation, as in gendo.RunWithBackend. As in
+// gendo.RunWithBackend, every line logged while serving filename is tagged
+// with it as the "pipeline" field.
+func New(filename, model, backend string) (*Server, error) {
+	logger := log.Default().With("pipeline", filename)
+
+	nodes, _, err := gendo.LoadScript(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	toolRegistry, llmRegistry := gendo.NewRegistries(model, backend, nodes, logger)
+
+	s := &Server{
+		nodes:        nodes,
+		toolRegistry: toolRegistry,
+		llmRegistry:  llmRegistry,
+		logger:       logger,
+	}
+
+	for _, node := range nodes {
+		if node.HTTPPath == "" {
+			continue
+		}
+		ops, err := ParseTemplate(node.HTTPPath)
+		if err != nil {
+			return nil, fmt.Errorf("node %d: invalid http path %q: %v", node.ID, node.HTTPPath, err)
+		}
+		s.routes = append(s.routes, route{method: node.HTTPMethod, ops: ops, node: node})
+	}
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler. It first tries the script's declared
+// "http" node bindings, then falls back to the generic POST /nodes/{id}
+// endpoint for any node.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if node, vars, ok := s.matchRoute(r); ok {
+		s.handleNode(w, r, node, vars)
+		return
+	}
+
+	if id, ok := nodeIDFromPath(r.URL.Path); ok {
+		if node, exists := s.nodes[id]; exists {
+			s.handleNode(w, r, node, nil)
+			return
+		}
+		http.Error(w, fmt.Sprintf("unknown node %d", id), http.StatusNotFound)
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+// matchRoute finds the first declared "http" binding whose method and path
+// template match the request.
+func (s *Server) matchRoute(r *http.Request) (gendo.Node, map[string]string, bool) {
+	for _, rt := range s.routes {
+		if rt.method != r.Method {
+			continue
+		}
+		if vars, ok := Match(rt.ops, r.URL.Path); ok {
+			return rt.node, vars, true
+		}
+	}
+	return gendo.Node{}, nil, false
+}
+
+// nodeIDFromPath extracts id from a "/nodes/{id}" path.
+func nodeIDFromPath(path string) (int, bool) {
+	const prefix = "/nodes/"
+	if !strings.HasPrefix(path, prefix) {
+		return 0, false
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(path, prefix))
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// handleNode builds the node's JSON input from any path captures plus the
+// request body, runs it through the node, and writes the result.
+func (s *Server) handleNode(w http.ResponseWriter, r *http.Request, node gendo.Node, vars map[string]string) {
+	if r.Method != http.MethodPost && node.HTTPPath == "" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	input, err := buildNodeInput(vars, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.logger.Debug("Serving node %d for %s %s with input: %q", node.ID, r.Method, r.URL.Path, input)
+
+	output, err := gendo.ProcessNode(node, input, s.toolRegistry, s.llmRegistry, s.logger)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, output)
+}
+
+// buildNodeInput merges path-captured variables with the request body into
+// a single JSON object, so a node bound to "http GET /users/{user}/summary"
+// sees {"user": "...", ...body fields}.
+func buildNodeInput(vars map[string]string, body []byte) (string, error) {
+	merged := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		merged[k] = v
+	}
+
+	if len(body) > 0 {
+		var bodyFields map[string]interface{}
+		if err := json.Unmarshal(body, &bodyFields); err != nil {
+			return "", fmt.Errorf("request body must be a JSON object: %v", err)
+		}
+		for k, v := range bodyFields {
+			merged[k] = v
+		}
+	}
+
+	out, err := json.Marshal(merged)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode node input: %v", err)
+	}
+	return string(out), nil
+}
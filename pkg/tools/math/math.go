@@ -1,203 +1,257 @@
 // Package math implements a mathematical expression evaluation tool for Gendo.
-// It provides functionality to parse and evaluate basic arithmetic expressions,
-// supporting addition, subtraction, multiplication, and division operations.
-// The tool can extract mathematical expressions from natural language input.
+// It supports standard arithmetic precedence, parentheses, unary minus, the
+// constants pi and e, and the built-in functions sqrt, abs, log, sin, cos,
+// min, max, and pow. The tool extracts the first expression it finds in
+// natural language input, including word operators like "plus" and "divided
+// by".
 package math
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"unicode"
 
 	"gendo/pkg/log"
 )
 
-// Tool implements the tools.Tool interface for math operations
-type Tool struct{}
-
-// NewTool creates a new math tool
-func NewTool() *Tool {
-	log.Debug("Creating new math tool")
-	return &Tool{}
+// mathRequest is the structured JSON form of a math tool call, matching the
+// "expression" argument declared in Schema.
+type mathRequest struct {
+	Expression string `json:"expression"`
 }
 
-// extractFirstExpression extracts the first mathematical expression from the input
-func extractFirstExpression(input string) string {
-	input = strings.TrimSpace(input)
-
-	// Remove quotes if present
-	input = strings.Trim(input, "\"")
+// Tool implements the tools.Tool interface for math operations
+type Tool struct {
+	logger *log.Logger
+}
 
-	// Try to extract a valid expression
-	if expr, ok := tryExtractExpression(input); ok {
-		return expr
+// NewTool creates a new math tool that logs through logger. A nil logger
+// falls back to log.Default().
+func NewTool(logger *log.Logger) *Tool {
+	if logger == nil {
+		logger = log.Default()
 	}
-
-	return ""
+	logger.Debug("Creating new math tool")
+	return &Tool{logger: logger}
 }
 
-// tryExtractExpression attempts to extract a valid mathematical expression from the input
-func tryExtractExpression(input string) (string, bool) {
-	var builder strings.Builder
-	var lastChar rune
-	var inNumber bool
-	var hasOperator bool
-	var foundDigit bool
+// Process implements the tools.Tool interface
+func (t *Tool) Process(input string) (string, error) {
+	t.logger.Debug("Processing math input: %q", input)
 
-	for i, char := range input {
-		switch {
-		case unicode.IsDigit(char) || char == '.':
-			builder.WriteRune(char)
-			inNumber = true
-			foundDigit = true
-		case char == '-':
-			// Allow minus sign at start or after another operator
-			if i == 0 || !unicode.IsDigit(rune(input[i-1])) {
-				builder.WriteRune(char)
-				inNumber = false
-			} else if inNumber {
-				builder.WriteRune(char)
-				hasOperator = true
-				inNumber = false
-			}
-		case char == '+' || char == '*' || char == '/':
-			if foundDigit {
-				builder.WriteRune(char)
-				hasOperator = true
-				inNumber = false
-			}
-		case unicode.IsSpace(char):
-			continue
-		default:
-			if foundDigit && !inNumber && !hasOperator {
-				continue
-			}
-			if !foundDigit {
-				continue
-			}
-			if hasOperator && !inNumber {
-				return "", false
-			}
-		}
-		lastChar = char
+	if req, ok := parseMathRequest(input); ok {
+		input = req.Expression
 	}
 
-	result := builder.String()
-	if result == "" {
-		return "", false
+	expr, err := extractFirstExpression(input)
+	if err != nil {
+		t.logger.Debug("Failed to extract a mathematical expression: %v", err)
+		return "", err
 	}
-
-	// Remove trailing operator if present
-	if lastChar == '+' || lastChar == '-' || lastChar == '*' || lastChar == '/' {
-		result = result[:len(result)-1]
+	if expr == "" {
+		t.logger.Debug("No valid mathematical expression found")
+		return "", fmt.Errorf("%w: no valid mathematical expression found", ErrParse)
 	}
+	t.logger.Debug("Extracted expression: %q", expr)
 
-	// Validate the expression
-	if _, _, _, err := parseExpression(result); err != nil {
-		return "", false
+	result, err := Eval(expr)
+	if err != nil {
+		t.logger.Debug("Failed to evaluate expression %q: %v", expr, err)
+		return "", err
 	}
 
-	return result, true
+	output := fmt.Sprintf("%g", result)
+	t.logger.Debug("Math result: %s", output)
+	return output, nil
 }
 
-// parseExpression parses a mathematical expression and returns the operands and operator
-func parseExpression(expr string) (float64, float64, rune, error) {
-	// Remove all spaces and quotes
-	expr = strings.ReplaceAll(expr, " ", "")
-	expr = strings.ReplaceAll(expr, "\"", "")
-
-	// Find the first operator that's not a leading minus sign
-	var operator rune
-	var operatorIndex int = -1
-
-	for i := 0; i < len(expr); i++ {
-		c := rune(expr[i])
-		if c == '+' || c == '*' || c == '/' || (c == '-' && i > 0 && expr[i-1] >= '0' && expr[i-1] <= '9') {
-			operator = c
-			operatorIndex = i
-			break
-		}
+// Description implements the tools.SchemaTool interface
+func (t *Tool) Description() string {
+	return "Evaluates an arithmetic expression (e.g. \"sqrt(16) + 2 * 3\") and returns the numeric result."
+}
+
+// Schema implements the tools.SchemaTool interface
+func (t *Tool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"expression": map[string]interface{}{
+				"type":        "string",
+				"description": "The arithmetic expression to evaluate, e.g. \"1 + 2 * 3\" or \"sqrt(16)\".",
+			},
+		},
+		"required": []string{"expression"},
 	}
+}
 
-	if operatorIndex == -1 {
-		return 0, 0, 0, fmt.Errorf("no valid operator found")
+// parseMathRequest decodes input as the structured {"expression": "..."}
+// form declared in Schema, as sent by the OpenAI function-calling layer. It
+// reports false if input isn't that JSON shape, in which case Process falls
+// back to treating input as natural language and runs it through
+// extractFirstExpression instead.
+func parseMathRequest(input string) (mathRequest, bool) {
+	var req mathRequest
+	if err := json.Unmarshal([]byte(input), &req); err != nil || req.Expression == "" {
+		return mathRequest{}, false
 	}
+	return req, true
+}
 
-	// Split into operands
-	first := expr[:operatorIndex]
-	second := expr[operatorIndex+1:]
+// extractFirstExpression finds the longest valid arithmetic expression at
+// the earliest position in input, tolerating surrounding natural-language
+// text (e.g. "What is 1 + 1?" yields "1+1"). It returns "", nil if input
+// contains no parseable expression at all. If the longest range it attempts
+// from a given start position (i.e. everything to the end of input) fails
+// for a reason other than unrelated trailing text - a real evaluation error
+// like division by zero, or an unknown function - that error is returned
+// instead of silently falling back to a shorter range that did evaluate
+// cleanly, since that longer range is almost certainly what was meant.
+func extractFirstExpression(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	input = strings.Trim(input, "\"")
 
-	// Parse operands
-	num1, err := strconv.ParseFloat(first, 64)
+	tokens, err := lex(sanitizeForLexing(input))
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("invalid first number: %v", err)
+		return "", nil
+	}
+	n := len(tokens) - 1 // exclude the trailing EOF marker
+	if n == 0 {
+		return "", nil
 	}
 
-	// Find the next operator in the second part
-	nextOpIndex := -1
-	for i := 0; i < len(second); i++ {
-		c := rune(second[i])
-		if c == '+' || c == '*' || c == '/' || (c == '-' && i > 0 && second[i-1] >= '0' && second[i-1] <= '9') {
-			nextOpIndex = i
-			break
+	for start := 0; start < n; start++ {
+		if !canStartExpression(tokens, start) {
+			continue
 		}
-	}
 
-	// If there's another operator, only take up to that point
-	if nextOpIndex != -1 {
-		second = second[:nextOpIndex]
-	}
+		best := ""
+		var longestErr error
+		for end := start + 1; end <= n; end++ {
+			_, evalErr := evalTokens(tokens[start:end])
+			if end == n {
+				longestErr = evalErr
+			}
+			if evalErr != nil {
+				continue
+			}
+			if candidate := renderTokens(tokens[start:end]); len(candidate) > len(best) {
+				best = candidate
+			}
+		}
 
-	num2, err := strconv.ParseFloat(second, 64)
-	if err != nil {
-		return 0, 0, 0, fmt.Errorf("invalid second number: %v", err)
+		if longestErr != nil && !errors.Is(longestErr, errTrailingTokens) {
+			return "", longestErr
+		}
+		if best != "" {
+			return best, nil
+		}
 	}
 
-	return num1, num2, operator, nil
+	return "", nil
 }
 
-// Process implements the tools.Tool interface
-func (t *Tool) Process(input string) (string, error) {
-	log.Debug("Processing math input: %q", input)
+// sanitizeForLexing blanks out any rune that isn't part of the expression
+// grammar (punctuation like "?" or ":"), so a full sentence can be lexed
+// without the lexer itself rejecting it for an unrelated stray character.
+func sanitizeForLexing(input string) string {
+	var b strings.Builder
+	for _, r := range input {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			b.WriteRune(r)
+		case strings.ContainsRune(".+-*/%^(),", r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
 
-	// Extract expression
-	expr := extractFirstExpression(input)
-	if expr == "" {
-		log.Debug("No valid mathematical expression found")
-		return "", fmt.Errorf("no valid mathematical expression found")
+// canStartExpression reports whether tokens[start] can begin a valid
+// expression, so extractFirstExpression doesn't waste time trying to grow
+// an expression from plain prose words like "What" or "is". An identifier
+// only counts if it's a known constant or a known function name followed by
+// "(" - an arbitrary word that happens to precede a parenthesised aside
+// (e.g. the "is" in "what is (1 + 2) * 3") must not be mistaken for the
+// start of a function call, or its inevitable ErrUnknownFunc would shadow
+// the real expression that follows it.
+func canStartExpression(tokens []token, start int) bool {
+	switch tokens[start].kind {
+	case tokNumber, tokLParen, tokMinus, tokPlus:
+		return true
+	case tokIdent:
+		name := strings.ToLower(tokens[start].text)
+		if start+1 < len(tokens) && tokens[start+1].kind == tokLParen {
+			_, isUnary := unaryFuncs[name]
+			_, isBinary := binaryFuncs[name]
+			return isUnary || isBinary
+		}
+		_, isConst := constants[name]
+		return isConst
+	default:
+		return false
 	}
+}
 
-	log.Debug("Extracted expression: %q", expr)
+// errTrailingTokens marks the specific evalTokens failure of otherwise
+// having parsed a complete expression with input left over, as opposed to a
+// genuine parse or evaluation error - the distinction extractFirstExpression
+// needs to tell unrelated trailing prose apart from a real error in what
+// was clearly meant to be the whole expression.
+var errTrailingTokens = errors.New("unexpected trailing tokens")
 
-	// Parse expression
-	num1, num2, operator, err := parseExpression(expr)
+// evalTokens evaluates tokens as a standalone expression, requiring it to
+// consume every token with none left over.
+func evalTokens(tokens []token) (float64, error) {
+	p := &parser{tokens: append(append([]token{}, tokens...), token{kind: tokEOF})}
+	result, err := p.parseExpr()
 	if err != nil {
-		log.Debug("Failed to parse expression: %v", err)
-		return "", err
+		return 0, err
+	}
+	if p.peek().kind != tokEOF {
+		return 0, fmt.Errorf("%w: %w: unexpected trailing token %q", ErrParse, errTrailingTokens, p.peek().text)
 	}
+	return result, nil
+}
 
-	// Perform calculation
-	var result float64
-	switch operator {
-	case '+':
-		result = num1 + num2
-	case '-':
-		result = num1 - num2
-	case '*':
-		result = num1 * num2
-	case '/':
-		if num2 == 0 {
-			log.Debug("Division by zero attempted")
-			return "", fmt.Errorf("division by zero")
-		}
-		result = num1 / num2
-	default:
-		log.Debug("Unsupported operator: %c", operator)
-		return "", fmt.Errorf("unsupported operator: %c", operator)
+// renderTokens renders tokens back to their canonical, space-free
+// expression text, translating word operators to their symbol.
+func renderTokens(tokens []token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(tokenText(t))
 	}
+	return b.String()
+}
 
-	output := fmt.Sprintf("%g", result)
-	log.Debug("Math result: %s", output)
-	return output, nil
+func tokenText(t token) string {
+	switch t.kind {
+	case tokNumber, tokIdent:
+		return t.text
+	case tokPlus:
+		return "+"
+	case tokMinus:
+		return "-"
+	case tokStar:
+		return "*"
+	case tokSlash:
+		return "/"
+	case tokPercent:
+		return "%"
+	case tokCaret:
+		return "^"
+	case tokLParen:
+		return "("
+	case tokRParen:
+		return ")"
+	case tokComma:
+		return ","
+	default:
+		return ""
+	}
 }
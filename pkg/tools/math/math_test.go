@@ -1,8 +1,9 @@
 package math
 
 import (
+	"errors"
+	"math"
 	"testing"
-	"strings"
 )
 
 func TestExtractFirstExpression(t *testing.T) {
@@ -71,6 +72,16 @@ func TestExtractFirstExpression(t *testing.T) {
 			input:    "5 minus 3",
 			expected: "5-3",
 		},
+		{
+			name:     "Expression with parentheses",
+			input:    "what is (1 + 2) * 3",
+			expected: "(1+2)*3",
+		},
+		{
+			name:     "Expression with a function call",
+			input:    "please compute sqrt(16)",
+			expected: "sqrt(16)",
+		},
 		{
 			name:     "No valid expression",
 			input:    "Hello world",
@@ -85,7 +96,10 @@ func TestExtractFirstExpression(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := extractFirstExpression(tt.input)
+			result, err := extractFirstExpression(tt.input)
+			if err != nil {
+				t.Fatalf("extractFirstExpression(%q) unexpected error: %v", tt.input, err)
+			}
 			if result != tt.expected {
 				t.Errorf("extractFirstExpression(%q) = %q, want %q", tt.input, result, tt.expected)
 			}
@@ -93,124 +107,114 @@ func TestExtractFirstExpression(t *testing.T) {
 	}
 }
 
-func TestParseExpression(t *testing.T) {
+func TestExtractFirstExpressionSurfacesEvaluationError(t *testing.T) {
+	if _, err := extractFirstExpression("10 divided by 0"); !errors.Is(err, ErrDivByZero) {
+		t.Errorf("extractFirstExpression(%q) error = %v, want error wrapping ErrDivByZero", "10 divided by 0", err)
+	}
+}
+
+func TestEval(t *testing.T) {
 	tests := []struct {
-		name           string
-		input          string
-		wantNum1       float64
-		wantNum2       float64
-		wantOperator   rune
-		wantErr        bool
-		wantErrMessage string
+		name    string
+		expr    string
+		want    float64
+		wantErr error
 	}{
-		{
-			name:         "Simple addition",
-			input:        "1+2",
-			wantNum1:     1,
-			wantNum2:     2,
-			wantOperator: '+',
-		},
-		{
-			name:         "Addition with spaces",
-			input:        "1 + 2",
-			wantNum1:     1,
-			wantNum2:     2,
-			wantOperator: '+',
-		},
-		{
-			name:         "Subtraction",
-			input:        "5-3",
-			wantNum1:     5,
-			wantNum2:     3,
-			wantOperator: '-',
-		},
-		{
-			name:         "Multiplication",
-			input:        "4*6",
-			wantNum1:     4,
-			wantNum2:     6,
-			wantOperator: '*',
-		},
-		{
-			name:         "Division",
-			input:        "8/2",
-			wantNum1:     8,
-			wantNum2:     2,
-			wantOperator: '/',
-		},
-		{
-			name:         "Negative numbers",
-			input:        "-1+-2",
-			wantNum1:     -1,
-			wantNum2:     -2,
-			wantOperator: '+',
-		},
-		{
-			name:           "No operator",
-			input:          "123",
-			wantErr:        true,
-			wantErrMessage: "no valid operator found",
-		},
-		{
-			name:           "Invalid first number",
-			input:          "abc+2",
-			wantErr:        true,
-			wantErrMessage: "invalid first number",
-		},
-		{
-			name:           "Invalid second number",
-			input:          "1+def",
-			wantErr:        true,
-			wantErrMessage: "invalid second number",
-		},
-		{
-			name:           "Empty input",
-			input:          "",
-			wantErr:        true,
-			wantErrMessage: "no valid operator found",
-		},
-		{
-			name:           "Multiple operators",
-			input:          "1+2+3",
-			wantNum1:       1,
-			wantNum2:       2,
-			wantOperator:   '+',
-		},
-		{
-			name:         "Decimal numbers",
-			input:        "1.5*2.3",
-			wantNum1:     1.5,
-			wantNum2:     2.3,
-			wantOperator: '*',
-		},
+		{name: "Addition", expr: "1+2", want: 3},
+		{name: "Operator precedence", expr: "1+2*3", want: 7},
+		{name: "Parentheses override precedence", expr: "(1+2)*3", want: 9},
+		{name: "Unary minus", expr: "-1+-2", want: -3},
+		{name: "Modulo", expr: "7%3", want: 1},
+		{name: "Exponentiation is right-associative", expr: "2^3^2", want: 512},
+		{name: "Decimal operands", expr: "1.5*2.3", want: 3.45},
+		{name: "Constant pi", expr: "pi", want: math.Pi},
+		{name: "Constant e", expr: "e", want: math.E},
+		{name: "Unary function sqrt", expr: "sqrt(16)", want: 4},
+		{name: "Unary function abs", expr: "abs(-5)", want: 5},
+		{name: "Binary function max", expr: "max(1,9)", want: 9},
+		{name: "Binary function pow", expr: "pow(2,10)", want: 1024},
+		{name: "Nested function calls", expr: "sqrt(max(4,16))", want: 4},
+		{name: "Division by zero", expr: "1/0", wantErr: ErrDivByZero},
+		{name: "Modulo by zero", expr: "1%0", wantErr: ErrDivByZero},
+		{name: "Unknown function", expr: "frobnicate(1)", wantErr: ErrUnknownFunc},
+		{name: "Unknown identifier", expr: "banana", wantErr: ErrUnknownFunc},
+		{name: "Trailing garbage", expr: "1+2)", wantErr: ErrParse},
+		{name: "Empty expression", expr: "", wantErr: ErrParse},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			num1, num2, operator, err := parseExpression(tt.input)
-			if tt.wantErr {
-				if err == nil {
-					t.Errorf("parseExpression(%q) expected error containing %q, got nil", tt.input, tt.wantErrMessage)
-					return
-				}
-				if tt.wantErrMessage != "" && !strings.Contains(err.Error(), tt.wantErrMessage) {
-					t.Errorf("parseExpression(%q) error = %v, want error containing %q", tt.input, err, tt.wantErrMessage)
+			got, err := Eval(tt.expr)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Eval(%q) error = %v, want error wrapping %v", tt.expr, err, tt.wantErr)
 				}
 				return
 			}
 			if err != nil {
-				t.Errorf("parseExpression(%q) unexpected error: %v", tt.input, err)
-				return
+				t.Fatalf("Eval(%q) unexpected error: %v", tt.expr, err)
 			}
-			if num1 != tt.wantNum1 {
-				t.Errorf("parseExpression(%q) num1 = %v, want %v", tt.input, num1, tt.wantNum1)
-			}
-			if num2 != tt.wantNum2 {
-				t.Errorf("parseExpression(%q) num2 = %v, want %v", tt.input, num2, tt.wantNum2)
-			}
-			if operator != tt.wantOperator {
-				t.Errorf("parseExpression(%q) operator = %q, want %q", tt.input, operator, tt.wantOperator)
+			// Runtime float64 arithmetic and a decimal literal of the same
+			// nominal value can differ in their last bit (e.g. 1.5*2.3
+			// computed at runtime vs. the constant 3.45), so compare with a
+			// small tolerance rather than requiring bit-exact equality.
+			const epsilon = 1e-9
+			if math.Abs(got-tt.want) > epsilon {
+				t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
 			}
 		})
 	}
-} 
\ No newline at end of file
+}
+
+func TestToolProcess(t *testing.T) {
+	tool := NewTool(nil)
+
+	got, err := tool.Process("What is sqrt(16) + 2 * 3?")
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if got != "10" {
+		t.Errorf("Process() = %q, want %q", got, "10")
+	}
+
+	if _, err := tool.Process("10 divided by 0"); !errors.Is(err, ErrDivByZero) {
+		t.Errorf("Process() error = %v, want error wrapping ErrDivByZero", err)
+	}
+
+	if _, err := tool.Process("Hello world"); !errors.Is(err, ErrParse) {
+		t.Errorf("Process() error = %v, want error wrapping ErrParse", err)
+	}
+}
+
+func TestToolProcessStructuredInput(t *testing.T) {
+	tool := NewTool(nil)
+
+	got, err := tool.Process(`{"expression": "1 + 2 * 3"}`)
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if got != "7" {
+		t.Errorf("Process() = %q, want %q", got, "7")
+	}
+
+	if _, err := tool.Process(`{"expression": "1/0"}`); !errors.Is(err, ErrDivByZero) {
+		t.Errorf("Process() error = %v, want error wrapping ErrDivByZero", err)
+	}
+}
+
+func TestToolSchema(t *testing.T) {
+	tool := NewTool(nil)
+
+	if tool.Description() == "" {
+		t.Error("Description() returned an empty string")
+	}
+
+	schema := tool.Schema()
+	props, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Schema() properties = %v, want a map", schema["properties"])
+	}
+	if _, ok := props["expression"]; !ok {
+		t.Error("Schema() properties missing \"expression\"")
+	}
+}
@@ -0,0 +1,392 @@
+package math
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrParse indicates the expression text could not be parsed as valid
+// arithmetic.
+var ErrParse = errors.New("invalid expression")
+
+// ErrDivByZero indicates a division or modulo by zero was attempted.
+var ErrDivByZero = errors.New("division by zero")
+
+// ErrUnknownFunc indicates a function or constant name that isn't one of the
+// built-ins.
+var ErrUnknownFunc = errors.New("unknown function")
+
+// tokenKind identifies the lexical category of a token.
+type tokenKind int
+
+const (
+	tokNumber tokenKind = iota
+	tokIdent
+	tokPlus
+	tokMinus
+	tokStar
+	tokSlash
+	tokPercent
+	tokCaret
+	tokLParen
+	tokRParen
+	tokComma
+	tokEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+}
+
+// wordOperators maps single natural-language operator words onto the
+// symbol they stand in for, so a Gendo script can write "5 plus 3" as
+// naturally as "5 + 3".
+var wordOperators = map[string]tokenKind{
+	"plus":   tokPlus,
+	"minus":  tokMinus,
+	"times":  tokStar,
+	"mod":    tokPercent,
+	"modulo": tokPercent,
+}
+
+// twoWordOperators maps two-word operator phrases onto the symbol they
+// stand in for.
+var twoWordOperators = map[[2]string]tokenKind{
+	{"divided", "by"}:    tokSlash,
+	{"multiplied", "by"}: tokStar,
+}
+
+// constants are the named values primary expressions may reference.
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// unaryFuncs are the built-in single-argument functions a call expression
+// may invoke.
+var unaryFuncs = map[string]func(float64) float64{
+	"sqrt": math.Sqrt,
+	"abs":  math.Abs,
+	"log":  math.Log,
+	"sin":  math.Sin,
+	"cos":  math.Cos,
+}
+
+// binaryFuncs are the built-in two-argument functions a call expression may
+// invoke.
+var binaryFuncs = map[string]func(float64, float64) float64{
+	"min": math.Min,
+	"max": math.Max,
+	"pow": math.Pow,
+}
+
+// lex tokenizes expr, recognizing numbers, identifiers (including the
+// natural-language operator words above), and the standard arithmetic
+// symbols. Any other character is rejected with ErrParse;
+// extractFirstExpression blanks such characters out before calling lex
+// (see sanitizeForLexing), while Eval passes its input through unchanged.
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+	i := 0
+	for i < len(runes) {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			n, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q is not a valid number", ErrParse, text)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, num: n})
+		case unicode.IsLetter(c):
+			start := i
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			if kind, ok := wordOperators[strings.ToLower(word)]; ok {
+				tokens = append(tokens, token{kind: kind, text: word})
+			} else {
+				tokens = append(tokens, token{kind: tokIdent, text: word})
+			}
+		case c == '+':
+			tokens = append(tokens, token{kind: tokPlus, text: "+"})
+			i++
+		case c == '-':
+			tokens = append(tokens, token{kind: tokMinus, text: "-"})
+			i++
+		case c == '*':
+			tokens = append(tokens, token{kind: tokStar, text: "*"})
+			i++
+		case c == '/':
+			tokens = append(tokens, token{kind: tokSlash, text: "/"})
+			i++
+		case c == '%':
+			tokens = append(tokens, token{kind: tokPercent, text: "%"})
+			i++
+		case c == '^':
+			tokens = append(tokens, token{kind: tokCaret, text: "^"})
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")"})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ","})
+			i++
+		default:
+			return nil, fmt.Errorf("%w: unexpected character %q", ErrParse, string(c))
+		}
+	}
+
+	tokens = foldTwoWordOperators(tokens)
+	tokens = append(tokens, token{kind: tokEOF})
+	return tokens, nil
+}
+
+// foldTwoWordOperators merges adjacent identifier pairs like "divided" "by"
+// into the single operator token they spell out.
+func foldTwoWordOperators(tokens []token) []token {
+	folded := make([]token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if i+1 < len(tokens) && tokens[i].kind == tokIdent && tokens[i+1].kind == tokIdent {
+			pair := [2]string{strings.ToLower(tokens[i].text), strings.ToLower(tokens[i+1].text)}
+			if kind, ok := twoWordOperators[pair]; ok {
+				folded = append(folded, token{kind: kind, text: tokens[i].text + " " + tokens[i+1].text})
+				i++
+				continue
+			}
+		}
+		folded = append(folded, tokens[i])
+	}
+	return folded
+}
+
+// parser is a recursive-descent, precedence-climbing parser/evaluator over
+// a token stream: expr := term (('+'|'-') term)*, term := unary
+// (('*'|'/'|'%') unary)*, unary := ('-'|'+') unary | power,
+// power := primary ('^' unary)?, primary := NUMBER | CONST |
+// IDENT '(' args ')' | '(' expr ')'.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	p.pos++
+	return t
+}
+
+func (p *parser) expect(kind tokenKind) (token, error) {
+	if p.peek().kind != kind {
+		return token{}, fmt.Errorf("%w: unexpected token %q", ErrParse, p.peek().text)
+	}
+	return p.next(), nil
+}
+
+// Eval parses and evaluates expr, a previously-extracted arithmetic
+// expression (see extractFirstExpression), supporting +, -, *, /, %, ^,
+// parentheses, unary +/-, the constants pi and e, and the built-in
+// functions sqrt, abs, log, sin, cos, min, max, and pow.
+func Eval(expr string) (float64, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return 0, err
+	}
+	p := &parser{tokens: tokens}
+	result, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	if p.peek().kind != tokEOF {
+		return 0, fmt.Errorf("%w: unexpected trailing token %q", ErrParse, p.peek().text)
+	}
+	return result, nil
+}
+
+func (p *parser) parseExpr() (float64, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokPlus:
+			p.next()
+			right, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			left += right
+		case tokMinus:
+			p.next()
+			right, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			left -= right
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *parser) parseTerm() (float64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokStar:
+			p.next()
+			right, err := p.parseUnary()
+			if err != nil {
+				return 0, err
+			}
+			left *= right
+		case tokSlash:
+			p.next()
+			right, err := p.parseUnary()
+			if err != nil {
+				return 0, err
+			}
+			if right == 0 {
+				return 0, ErrDivByZero
+			}
+			left /= right
+		case tokPercent:
+			p.next()
+			right, err := p.parseUnary()
+			if err != nil {
+				return 0, err
+			}
+			if right == 0 {
+				return 0, ErrDivByZero
+			}
+			left = math.Mod(left, right)
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *parser) parseUnary() (float64, error) {
+	switch p.peek().kind {
+	case tokMinus:
+		p.next()
+		v, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		return -v, nil
+	case tokPlus:
+		p.next()
+		return p.parseUnary()
+	default:
+		return p.parsePower()
+	}
+}
+
+// parsePower handles '^', right-associatively, so 2^3^2 is 2^(3^2).
+func (p *parser) parsePower() (float64, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return 0, err
+	}
+	if p.peek().kind == tokCaret {
+		p.next()
+		exp, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(base, exp), nil
+	}
+	return base, nil
+}
+
+func (p *parser) parsePrimary() (float64, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokNumber:
+		p.next()
+		return tok.num, nil
+	case tokLParen:
+		p.next()
+		v, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if _, err := p.expect(tokRParen); err != nil {
+			return 0, err
+		}
+		return v, nil
+	case tokIdent:
+		p.next()
+		name := strings.ToLower(tok.text)
+		if p.peek().kind == tokLParen {
+			return p.parseCall(name)
+		}
+		if v, ok := constants[name]; ok {
+			return v, nil
+		}
+		return 0, fmt.Errorf("%w: %q", ErrUnknownFunc, tok.text)
+	default:
+		return 0, fmt.Errorf("%w: unexpected token %q", ErrParse, tok.text)
+	}
+}
+
+func (p *parser) parseCall(name string) (float64, error) {
+	if _, err := p.expect(tokLParen); err != nil {
+		return 0, err
+	}
+	var args []float64
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if _, err := p.expect(tokRParen); err != nil {
+		return 0, err
+	}
+
+	if fn, ok := unaryFuncs[name]; ok {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("%w: %s expects 1 argument, got %d", ErrParse, name, len(args))
+		}
+		return fn(args[0]), nil
+	}
+	if fn, ok := binaryFuncs[name]; ok {
+		if len(args) != 2 {
+			return 0, fmt.Errorf("%w: %s expects 2 arguments, got %d", ErrParse, name, len(args))
+		}
+		return fn(args[0], args[1]), nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownFunc, name)
+}
@@ -11,38 +11,44 @@ import (
 
 // Tool implements the tools.Tool interface for random number generation
 type Tool struct {
-	rand *rand.Rand
+	rand   *rand.Rand
+	logger *log.Logger
 }
 
-// New creates a new random number generator tool
-func New() *Tool {
-	log.Debug("Creating new random number generator tool")
+// New creates a new random number generator tool that logs through logger.
+// A nil logger falls back to log.Default().
+func New(logger *log.Logger) *Tool {
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Debug("Creating new random number generator tool")
 	source := rand.NewSource(time.Now().UnixNano())
 	return &Tool{
-		rand: rand.New(source),
+		rand:   rand.New(source),
+		logger: logger,
 	}
 }
 
 // Process implements the tools.Tool interface
 func (t *Tool) Process(input string) (string, error) {
-	log.Debug("Processing random input: %q", input)
-	
+	t.logger.Debug("Processing random input: %q", input)
+
 	// Parse the max number
 	max, err := strconv.ParseInt(input, 10, 64)
 	if err != nil {
-		log.Debug("Failed to parse max number %q: %v", input, err)
+		t.logger.Debug("Failed to parse max number %q: %v", input, err)
 		return "", fmt.Errorf("invalid max number: %v", err)
 	}
-	
+
 	if max <= 0 {
-		log.Debug("Invalid max number: %d (must be positive)", max)
+		t.logger.Debug("Invalid max number: %d (must be positive)", max)
 		return "", fmt.Errorf("max number must be positive")
 	}
-	
+
 	// Generate random number
 	result := t.rand.Int63n(max)
 	output := fmt.Sprintf("%d", result)
-	
-	log.Debug("Generated random number: %s (max: %d)", output, max)
+
+	t.logger.Debug("Generated random number: %s (max: %d)", output, max)
 	return output, nil
-} 
\ No newline at end of file
+}
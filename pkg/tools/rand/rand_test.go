@@ -9,17 +9,17 @@ import (
 )
 
 func TestNew(t *testing.T) {
-	tool := New()
+	tool := New(nil)
 	if tool == nil {
-		t.Error("New() returned nil")
+		t.Error("New(nil) returned nil")
 	}
 	if tool.rand == nil {
-		t.Error("New() returned tool with nil rand")
+		t.Error("New(nil) returned tool with nil rand")
 	}
 }
 
 func TestProcess(t *testing.T) {
-	tool := New()
+	tool := New(nil)
 
 	tests := []struct {
 		name    string
@@ -80,7 +80,7 @@ func TestProcess(t *testing.T) {
 }
 
 func TestProcessDistribution(t *testing.T) {
-	tool := New()
+	tool := New(nil)
 	max := int64(10)
 	iterations := 1000
 	counts := make(map[int64]int)
@@ -4,47 +4,101 @@
 package read
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"net/http"
+
+	"github.com/spf13/afero"
 
 	"gendo/pkg/log"
+	"gendo/pkg/tools/sandbox"
 )
 
+// maxReadBytes bounds how much of a single file Process will return, so a
+// Gendo script can't be pointed at an arbitrarily large file and exhaust
+// memory.
+const maxReadBytes = 10 * 1024 * 1024 // 10 MiB
+
+// readRequest is the structured JSON form of a read tool call.
+type readRequest struct {
+	Path string `json:"path"`
+}
+
 // ReadTool implements the tools.Tool interface for file reading
 type ReadTool struct {
-	basePath string
+	fs     afero.Fs
+	logger *log.Logger
 }
 
-// NewReadTool creates a new file reading tool
-func NewReadTool(basePath string) *ReadTool {
-	log.Debug("Creating new read tool with base path: %q", basePath)
+// NewReadTool creates a new file reading tool backed by the real OS
+// filesystem. It is a thin wrapper over NewReadToolFS for the common case;
+// use NewReadToolFS directly to read from an afero.Fs such as
+// afero.NewMemMapFs() (tests) or afero.NewReadOnlyFs(afero.NewOsFs())
+// (defense in depth for a tool that should never write).
+func NewReadTool(basePath string, logger *log.Logger) *ReadTool {
+	return NewReadToolFS(afero.NewOsFs(), basePath, logger)
+}
+
+// NewReadToolFS creates a new file reading tool backed by fs, confined to
+// basePath via a sandbox.BasePathFs, and logging through logger. An empty
+// basePath disables confinement, reading relative to fs's own root. A nil
+// logger falls back to log.Default().
+func NewReadToolFS(fs afero.Fs, basePath string, logger *log.Logger) *ReadTool {
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Debug("Creating new read tool with base path: %q", basePath)
 	return &ReadTool{
-		basePath: basePath,
+		fs:     sandbox.NewBasePathFs(fs, basePath),
+		logger: logger,
 	}
 }
 
-// Process implements the tools.Tool interface for ReadTool
+// Process implements the tools.Tool interface for ReadTool. input may be a
+// JSON object {"path": "..."}, or, for backward compatibility with scripts
+// written before structured input was supported, a bare file path.
 func (t *ReadTool) Process(input string) (string, error) {
-	log.Debug("Processing read input: %q", input)
+	t.logger.Debug("Processing read input: %q", input)
 
-	if input == "" {
-		log.Debug("Empty input provided")
+	path := parseReadInput(input)
+	if path == "" {
+		t.logger.Debug("Empty input provided")
 		return "", fmt.Errorf("no file path provided")
 	}
 
-	filePath := input
-	if t.basePath != "" {
-		filePath = filepath.Join(t.basePath, input)
-		log.Debug("Using full file path: %q", filePath)
+	info, err := t.fs.Stat(path)
+	if err != nil {
+		t.logger.Debug("Failed to stat file %q: %v", path, err)
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	if info.Size() > maxReadBytes {
+		return "", fmt.Errorf("file %q exceeds the %d byte read limit", path, maxReadBytes)
 	}
 
-	content, err := os.ReadFile(filePath)
+	content, err := afero.ReadFile(t.fs, path)
 	if err != nil {
-		log.Debug("Failed to read file %q: %v", filePath, err)
+		t.logger.Debug("Failed to read file %q: %v", path, err)
 		return "", fmt.Errorf("failed to read file: %v", err)
 	}
 
-	log.Debug("Successfully read %d bytes from %q", len(content), filePath)
+	t.logger.Debug("Successfully read %d bytes from %q", len(content), path)
 	return string(content), nil
 }
+
+// parseReadInput accepts either a JSON {"path": "..."} object or a bare file
+// path, for backward compatibility with scripts written before structured
+// input was supported.
+func parseReadInput(input string) string {
+	var req readRequest
+	if err := json.Unmarshal([]byte(input), &req); err == nil && req.Path != "" {
+		return req.Path
+	}
+	return input
+}
+
+// FileSystem exposes the tool's sandboxed base path as an http.FileSystem,
+// for callers that want to serve files through the same base directory and
+// backing afero.Fs this tool reads from.
+func (t *ReadTool) FileSystem() http.FileSystem {
+	return afero.NewHttpFs(t.fs).Dir("")
+}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/spf13/afero"
 )
 
 func TestReadTool(t *testing.T) {
@@ -54,7 +56,7 @@ func TestReadTool(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tool := NewReadTool(tt.basePath)
+			tool := NewReadTool(tt.basePath, nil)
 			got, err := tool.Process(tt.input)
 
 			if tt.wantErr {
@@ -76,6 +78,107 @@ func TestReadTool(t *testing.T) {
 	}
 }
 
+func TestReadToolJSONInput(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gendo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	testContent := "json input content"
+	if err := os.WriteFile(filepath.Join(tmpDir, "test.txt"), []byte(testContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tool := NewReadTool(tmpDir, nil)
+	got, err := tool.Process(`{"path":"test.txt"}`)
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if got != testContent {
+		t.Errorf("Process() = %q, want %q", got, testContent)
+	}
+}
+
+func TestReadToolPathEscape(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gendo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "relative traversal", input: "../etc/passwd"},
+		{name: "absolute path", input: "/etc/passwd"},
+	}
+
+	tool := NewReadTool(tmpDir, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tool.Process(tt.input); err == nil {
+				t.Errorf("Process(%q) expected error, got none", tt.input)
+			}
+		})
+	}
+}
+
+func TestReadToolUnicodeFilename(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gendo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	testContent := "unicode content"
+	if err := os.WriteFile(filepath.Join(tmpDir, "ファイル.txt"), []byte(testContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tool := NewReadTool(tmpDir, nil)
+	got, err := tool.Process("ファイル.txt")
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if got != testContent {
+		t.Errorf("Process() = %q, want %q", got, testContent)
+	}
+}
+
+func TestReadToolFS(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/data/test.txt", []byte("mem content"), 0644); err != nil {
+		t.Fatalf("Failed to seed mem-map filesystem: %v", err)
+	}
+
+	tool := NewReadToolFS(fs, "/data", nil)
+	got, err := tool.Process("test.txt")
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if got != "mem content" {
+		t.Errorf("Process() = %q, want %q", got, "mem content")
+	}
+}
+
+func TestReadToolReadOnlyFS(t *testing.T) {
+	base := afero.NewMemMapFs()
+	if err := afero.WriteFile(base, "/data/test.txt", []byte("read only"), 0644); err != nil {
+		t.Fatalf("Failed to seed mem-map filesystem: %v", err)
+	}
+
+	tool := NewReadToolFS(afero.NewReadOnlyFs(base), "/data", nil)
+	got, err := tool.Process("test.txt")
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if got != "read only" {
+		t.Errorf("Process() = %q, want %q", got, "read only")
+	}
+}
+
 func BenchmarkReadTool(b *testing.B) {
 	// Create a temporary directory for benchmark files
 	tmpDir, err := os.MkdirTemp("", "gendo-bench-*")
@@ -99,7 +202,7 @@ func BenchmarkReadTool(b *testing.B) {
 		}
 	}
 
-	tool := NewReadTool(tmpDir)
+	tool := NewReadTool(tmpDir, nil)
 
 	for filename := range files {
 		b.Run(filename, func(b *testing.B) {
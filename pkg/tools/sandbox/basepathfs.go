@@ -0,0 +1,154 @@
+package sandbox
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// BasePathFs is a chroot-style afero.Fs wrapper that confines every
+// operation to a base directory within another afero.Fs, modeled on
+// afero's own BasePathFs but routed through Resolve so every path is also
+// checked for ".." escapes, absolute paths, and symlinks that would resolve
+// outside the base directory. Resolve evaluates symlinks with
+// filepath.EvalSymlinks on the host filesystem, whatever afero.Fs source
+// is; symlinks inside a non-OS source are not examined.
+type BasePathFs struct {
+	source   afero.Fs
+	basePath string
+}
+
+// NewBasePathFs returns an afero.Fs that confines all paths given to it to
+// basePath within source. An empty basePath disables sandboxing and returns
+// source unchanged, matching Resolve's behavior.
+func NewBasePathFs(source afero.Fs, basePath string) afero.Fs {
+	if basePath == "" {
+		return source
+	}
+	return &BasePathFs{source: source, basePath: basePath}
+}
+
+// RealPath resolves name to its real, confined path within the base
+// directory, or an error wrapping ErrPathOutsideBase if name would escape
+// it.
+func (b *BasePathFs) RealPath(name string) (string, error) {
+	return Resolve(b.basePath, name)
+}
+
+// Name implements afero.Fs.
+func (b *BasePathFs) Name() string {
+	return "BasePathFs:" + b.basePath
+}
+
+// Create implements afero.Fs.
+func (b *BasePathFs) Create(name string) (afero.File, error) {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.source.Create(path)
+}
+
+// Mkdir implements afero.Fs.
+func (b *BasePathFs) Mkdir(name string, perm os.FileMode) error {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return err
+	}
+	return b.source.Mkdir(path, perm)
+}
+
+// MkdirAll implements afero.Fs.
+func (b *BasePathFs) MkdirAll(name string, perm os.FileMode) error {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return err
+	}
+	return b.source.MkdirAll(path, perm)
+}
+
+// Open implements afero.Fs.
+func (b *BasePathFs) Open(name string) (afero.File, error) {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.source.Open(path)
+}
+
+// OpenFile implements afero.Fs.
+func (b *BasePathFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.source.OpenFile(path, flag, perm)
+}
+
+// Remove implements afero.Fs.
+func (b *BasePathFs) Remove(name string) error {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return err
+	}
+	return b.source.Remove(path)
+}
+
+// RemoveAll implements afero.Fs.
+func (b *BasePathFs) RemoveAll(name string) error {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return err
+	}
+	return b.source.RemoveAll(path)
+}
+
+// Rename implements afero.Fs.
+func (b *BasePathFs) Rename(oldname, newname string) error {
+	oldPath, err := b.RealPath(oldname)
+	if err != nil {
+		return err
+	}
+	newPath, err := b.RealPath(newname)
+	if err != nil {
+		return err
+	}
+	return b.source.Rename(oldPath, newPath)
+}
+
+// Stat implements afero.Fs.
+func (b *BasePathFs) Stat(name string) (os.FileInfo, error) {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.source.Stat(path)
+}
+
+// Chmod implements afero.Fs.
+func (b *BasePathFs) Chmod(name string, mode os.FileMode) error {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return err
+	}
+	return b.source.Chmod(path, mode)
+}
+
+// Chtimes implements afero.Fs.
+func (b *BasePathFs) Chtimes(name string, atime, mtime time.Time) error {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return err
+	}
+	return b.source.Chtimes(path, atime, mtime)
+}
+
+// Chown implements afero.Fs.
+func (b *BasePathFs) Chown(name string, uid, gid int) error {
+	path, err := b.RealPath(name)
+	if err != nil {
+		return err
+	}
+	return b.source.Chown(path, uid, gid)
+}
@@ -0,0 +1,64 @@
+package sandbox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestBasePathFs(t *testing.T) {
+	source := afero.NewMemMapFs()
+	if err := afero.WriteFile(source, "/data/file.txt", []byte("ok"), 0644); err != nil {
+		t.Fatalf("Failed to seed mem-map filesystem: %v", err)
+	}
+	if err := afero.WriteFile(source, "/secret.txt", []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to seed mem-map filesystem: %v", err)
+	}
+
+	fs := NewBasePathFs(source, "/data")
+
+	t.Run("reads within base", func(t *testing.T) {
+		content, err := afero.ReadFile(fs, "file.txt")
+		if err != nil {
+			t.Fatalf("ReadFile() unexpected error: %v", err)
+		}
+		if string(content) != "ok" {
+			t.Errorf("ReadFile() = %q, want %q", string(content), "ok")
+		}
+	})
+
+	t.Run("traversal escape", func(t *testing.T) {
+		_, err := afero.ReadFile(fs, "../secret.txt")
+		if !errors.Is(err, ErrPathOutsideBase) {
+			t.Errorf("ReadFile() error = %v, want ErrPathOutsideBase", err)
+		}
+	})
+
+	t.Run("absolute path escape", func(t *testing.T) {
+		_, err := afero.ReadFile(fs, "/secret.txt")
+		if !errors.Is(err, ErrPathOutsideBase) {
+			t.Errorf("ReadFile() error = %v, want ErrPathOutsideBase", err)
+		}
+	})
+
+	t.Run("write within base", func(t *testing.T) {
+		if err := afero.WriteFile(fs, "new.txt", []byte("new"), 0644); err != nil {
+			t.Fatalf("WriteFile() unexpected error: %v", err)
+		}
+		content, err := afero.ReadFile(source, "/data/new.txt")
+		if err != nil {
+			t.Fatalf("Failed to read back written file from source fs: %v", err)
+		}
+		if string(content) != "new" {
+			t.Errorf("written content = %q, want %q", string(content), "new")
+		}
+	})
+
+	t.Run("empty base path disables sandboxing", func(t *testing.T) {
+		unsandboxed := NewBasePathFs(source, "")
+		if _, ok := unsandboxed.(*BasePathFs); ok {
+			t.Error("NewBasePathFs(_, \"\") should return source unchanged, not a *BasePathFs")
+		}
+	})
+}
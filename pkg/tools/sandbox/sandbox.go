@@ -0,0 +1,91 @@
+// Package sandbox provides a shared path-confinement helper for Gendo's
+// filesystem tools (read, write), so a script cannot escape its configured
+// base directory via "../" segments, an absolute path, or a symlink.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPathOutsideBase is returned when a requested path would resolve outside
+// its tool's configured base directory.
+var ErrPathOutsideBase = errors.New("path escapes base directory")
+
+// Resolve joins input onto basePath and rejects any result that would escape
+// basePath via ".." segments, an absolute path, or a symlink. An empty
+// basePath disables sandboxing entirely, matching the tools' pre-existing
+// behavior of treating input as a path relative to the process's own
+// working directory.
+func Resolve(basePath, input string) (string, error) {
+	if basePath == "" {
+		return input, nil
+	}
+	if filepath.IsAbs(input) {
+		return "", fmt.Errorf("%w: %q is an absolute path", ErrPathOutsideBase, input)
+	}
+
+	cleanBase := filepath.Clean(basePath)
+	joined := filepath.Join(cleanBase, input)
+
+	if !withinBase(cleanBase, joined) {
+		return "", fmt.Errorf("%w: %q", ErrPathOutsideBase, input)
+	}
+
+	resolvedBase := cleanBase
+	if resolved, err := filepath.EvalSymlinks(cleanBase); err == nil {
+		resolvedBase = resolved
+	}
+
+	if err := checkSymlinkComponents(resolvedBase, cleanBase, joined, input); err != nil {
+		return "", err
+	}
+
+	return joined, nil
+}
+
+// checkSymlinkComponents walks joined's path components below cleanBase one
+// directory at a time, rejecting the first one that exists and resolves (via
+// filepath.EvalSymlinks) outside resolvedBase. Evaluating only the whole
+// path, as Resolve once did, misses this: EvalSymlinks errors whenever the
+// final component doesn't exist yet, which is exactly the case for every
+// file a write tool is about to create, so a symlink planted at any
+// directory level in between was never checked at all. A component that
+// doesn't exist yet (typically the final, not-yet-created file) can't be a
+// symlink and is skipped rather than treated as an error.
+func checkSymlinkComponents(resolvedBase, cleanBase, joined, input string) error {
+	rel, err := filepath.Rel(cleanBase, joined)
+	if err != nil || rel == "." {
+		return nil
+	}
+
+	current := cleanBase
+	for _, seg := range strings.Split(rel, string(filepath.Separator)) {
+		if seg == "" || seg == "." {
+			continue
+		}
+		current = filepath.Join(current, seg)
+
+		resolved, err := filepath.EvalSymlinks(current)
+		if err != nil {
+			// Doesn't exist (yet): nothing to resolve, and nothing beneath
+			// a nonexistent directory can exist either, so we're done.
+			return nil
+		}
+		if !withinBase(resolvedBase, resolved) {
+			return fmt.Errorf("%w: %q resolves outside the base directory via a symlink", ErrPathOutsideBase, input)
+		}
+	}
+	return nil
+}
+
+// withinBase reports whether path is base itself or lives underneath it.
+func withinBase(base, path string) bool {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
@@ -0,0 +1,79 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gendo-sandbox-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create subdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "sub", "file.txt"), []byte("ok"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	outsideDir, err := os.MkdirTemp("", "gendo-sandbox-outside-*")
+	if err != nil {
+		t.Fatalf("Failed to create outside dir: %v", err)
+	}
+	defer os.RemoveAll(outsideDir)
+	if err := os.WriteFile(filepath.Join(outsideDir, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to create outside file: %v", err)
+	}
+
+	escapeLink := filepath.Join(tmpDir, "escape")
+	if err := os.Symlink(outsideDir, escapeLink); err != nil {
+		t.Skipf("symlinks unsupported on this platform: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "plain file", input: "sub/file.txt"},
+		{name: "unicode filename", input: "sub/ファイル.txt"},
+		{name: "dot segment that stays inside", input: "sub/../sub/file.txt"},
+		{name: "traversal escape", input: "../secret.txt", wantErr: true},
+		{name: "deep traversal escape", input: "sub/../../secret.txt", wantErr: true},
+		{name: "absolute path", input: "/etc/passwd", wantErr: true},
+		{name: "symlink escape", input: "escape/secret.txt", wantErr: true},
+		{name: "symlink escape to not-yet-created file", input: "escape/newfile.txt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tmpDir, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Resolve(%q) = %q, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) unexpected error: %v", tt.input, err)
+			}
+			if !withinBase(filepath.Clean(tmpDir), got) {
+				t.Errorf("Resolve(%q) = %q, escapes base %q", tt.input, got, tmpDir)
+			}
+		})
+	}
+}
+
+func TestResolveNoBasePath(t *testing.T) {
+	got, err := Resolve("", "../anything")
+	if err != nil {
+		t.Fatalf("Resolve() with empty basePath unexpected error: %v", err)
+	}
+	if got != "../anything" {
+		t.Errorf("Resolve() = %q, want input unchanged", got)
+	}
+}
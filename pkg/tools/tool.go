@@ -9,6 +9,63 @@ type Tool interface {
 	Process(input string) (string, error)
 }
 
+// SchemaTool is an optional extension a Tool can implement to describe its
+// input as a JSON Schema. LLM backends that support native function-calling
+// use this to advertise the tool and its typed parameters to the model,
+// instead of passing it a single opaque input string.
+type SchemaTool interface {
+	Tool
+
+	// Schema returns a JSON Schema object describing the tool's arguments,
+	// suitable for embedding in a function/tool-calling request.
+	Schema() map[string]interface{}
+
+	// Description returns a short human-readable summary of what the tool
+	// does, used as the function-calling "description" field.
+	Description() string
+}
+
+// Token is a single chunk of a StreamingTool's output, mirroring llm.Token
+// so a "stream " node (e.g. "3 : stream tool write") can treat a streaming tool and a streaming LLM
+// the same way. The channel closes after a Token with Done true, or one
+// carrying a non-nil Err, is sent.
+type Token struct {
+	Text string
+	Err  error
+	Done bool
+}
+
+// StreamingTool is an optional extension a Tool can implement to produce its
+// output incrementally instead of materializing it all before returning,
+// e.g. write emitting a progress Token per chunk written. Tools that don't
+// implement it are adapted by buffering, see ProcessStream.
+type StreamingTool interface {
+	Tool
+
+	// ProcessStream takes input text and returns a channel of Tokens as the
+	// tool produces output.
+	ProcessStream(input string) (<-chan Token, error)
+}
+
+// ProcessStream adapts any Tool to the streaming Token-channel shape,
+// calling tool.ProcessStream directly if tool implements StreamingTool, or
+// buffering tool.Process's result into a single Token otherwise.
+func ProcessStream(tool Tool, input string) (<-chan Token, error) {
+	if st, ok := tool.(StreamingTool); ok {
+		return st.ProcessStream(input)
+	}
+
+	result, err := tool.Process(input)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Token, 1)
+	ch <- Token{Text: result, Done: true}
+	close(ch)
+	return ch, nil
+}
+
 // Registry is a map of tool names to their implementations
 type Registry map[string]Tool
 
@@ -0,0 +1,253 @@
+// Package webdav implements a Gendo tool that reads and writes files on a
+// remote WebDAV server, for scripts that need durable storage beyond the
+// local filesystem.
+package webdav
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"gendo/pkg/log"
+)
+
+// Tool implements the tools.Tool interface against a WebDAV server.
+type Tool struct {
+	baseURL    string
+	httpClient *http.Client
+	username   string
+	password   string
+	tlsCert    *tls.Certificate
+	logger     *log.Logger
+}
+
+// Option configures a Tool.
+type Option func(*Tool)
+
+// WithBasicAuth sets HTTP Basic credentials used on every request.
+func WithBasicAuth(username, password string) Option {
+	return func(t *Tool) {
+		t.username = username
+		t.password = password
+	}
+}
+
+// WithHTTPClient overrides the *http.Client used for requests, e.g. in
+// tests that point it at an httptest.Server.
+func WithHTTPClient(client *http.Client) Option {
+	return func(t *Tool) {
+		t.httpClient = client
+	}
+}
+
+// WithClientCert configures a TLS client certificate presented on every
+// request, for WebDAV servers that authenticate via mutual TLS instead of
+// (or in addition to) WithBasicAuth. It's applied to the tool's http.Client
+// after all options run (see New), so it composes with WithHTTPClient
+// regardless of which option is passed first.
+func WithClientCert(cert tls.Certificate) Option {
+	return func(t *Tool) {
+		t.tlsCert = &cert
+	}
+}
+
+// WithLogger sets the logger this Tool logs through. Without it, New falls
+// back to log.Default().
+func WithLogger(logger *log.Logger) Option {
+	return func(t *Tool) {
+		t.logger = logger
+	}
+}
+
+// New creates a Tool that talks to the WebDAV server at baseURL.
+func New(baseURL string, opts ...Option) *Tool {
+	t := &Tool{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{},
+		logger:     log.Default(),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.tlsCert != nil {
+		t.httpClient.Transport = transportWithClientCert(t.httpClient.Transport, *t.tlsCert)
+	}
+	return t
+}
+
+// transportWithClientCert clones base and adds cert to its TLS client
+// certificates, so WithClientCert doesn't clobber an *http.Transport set for
+// other reasons (proxies, timeouts). If base is nil or any other
+// http.RoundTripper, a clone of http.DefaultTransport is used instead and
+// base is replaced.
+func transportWithClientCert(base http.RoundTripper, cert tls.Certificate) http.RoundTripper {
+	transport, ok := base.(*http.Transport)
+	if !ok || transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	} else {
+		transport.TLSClientConfig = transport.TLSClientConfig.Clone()
+	}
+	transport.TLSClientConfig.Certificates = append(transport.TLSClientConfig.Certificates, cert)
+	return transport
+}
+
+// request is the structured JSON form of a webdav tool call.
+type request struct {
+	Op      string `json:"op"`
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+}
+
+// Process implements the tools.Tool interface. input is a JSON object
+// {"op": "get|put|mkcol|delete|stat", "path": "...", "content": "..."}; op
+// defaults to "get" when omitted, so a bare path (or {"path": "..."}) reads
+// a file, matching the read/write tools' legacy plain-path convention.
+func (t *Tool) Process(input string) (string, error) {
+	req, err := parseRequest(input)
+	if err != nil {
+		return "", err
+	}
+
+	switch strings.ToLower(req.Op) {
+	case "", "get":
+		return t.get(req.Path)
+	case "put":
+		return t.put(req.Path, req.Content)
+	case "mkcol":
+		return t.mkcol(req.Path)
+	case "delete":
+		return t.delete(req.Path)
+	case "stat", "propfind":
+		return t.propfind(req.Path)
+	default:
+		return "", fmt.Errorf("unknown webdav operation: %q", req.Op)
+	}
+}
+
+// parseRequest accepts a JSON {"op": ..., "path": ..., "content": ...}
+// object, or, for backward compatibility with scripts written before
+// structured input was supported, a bare file path treated as a "get".
+func parseRequest(input string) (request, error) {
+	var req request
+	if err := json.Unmarshal([]byte(input), &req); err == nil && req.Path != "" {
+		return req, nil
+	}
+	if input == "" {
+		return request{}, fmt.Errorf("no path provided")
+	}
+	return request{Op: "get", Path: input}, nil
+}
+
+// url builds the absolute request URL for the WebDAV-relative path p.
+func (t *Tool) url(p string) string {
+	return t.baseURL + "/" + strings.TrimLeft(path.Clean("/"+p), "/")
+}
+
+// do issues method against p with the given body, applying basic auth if
+// configured.
+func (t *Tool) do(method, p string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, t.url(p), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s request: %v", method, err)
+	}
+	if t.username != "" {
+		req.SetBasicAuth(t.username, t.password)
+	}
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %v", method, p, err)
+	}
+	return resp, nil
+}
+
+func (t *Tool) get(p string) (string, error) {
+	t.logger.Debug("WebDAV GET %s", p)
+	resp, err := t.do(http.MethodGet, p, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("webdav GET %s returned status %d", p, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read webdav response body: %v", err)
+	}
+	return string(body), nil
+}
+
+func (t *Tool) put(p, content string) (string, error) {
+	t.logger.Debug("WebDAV PUT %s (%d bytes)", p, len(content))
+	resp, err := t.do(http.MethodPut, p, strings.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return "", fmt.Errorf("webdav PUT %s returned status %d", p, resp.StatusCode)
+	}
+	return fmt.Sprintf("Successfully wrote to %s", p), nil
+}
+
+func (t *Tool) mkcol(p string) (string, error) {
+	t.logger.Debug("WebDAV MKCOL %s", p)
+	resp, err := t.do("MKCOL", p, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("webdav MKCOL %s returned status %d", p, resp.StatusCode)
+	}
+	return fmt.Sprintf("Successfully created collection %s", p), nil
+}
+
+func (t *Tool) delete(p string) (string, error) {
+	t.logger.Debug("WebDAV DELETE %s", p)
+	resp, err := t.do(http.MethodDelete, p, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return "", fmt.Errorf("webdav DELETE %s returned status %d", p, resp.StatusCode)
+	}
+	return fmt.Sprintf("Successfully deleted %s", p), nil
+}
+
+// propfind queries p's properties with Depth: 0 and returns the raw
+// multi-status XML body, letting callers (or a future node) inspect it for
+// existence or metadata without this tool parsing the XML itself.
+func (t *Tool) propfind(p string) (string, error) {
+	t.logger.Debug("WebDAV PROPFIND %s", p)
+	req, err := http.NewRequest("PROPFIND", t.url(p), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build PROPFIND request: %v", err)
+	}
+	req.Header.Set("Depth", "0")
+	if t.username != "" {
+		req.SetBasicAuth(t.username, t.password)
+	}
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("PROPFIND %s failed: %v", p, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return "", fmt.Errorf("webdav PROPFIND %s returned status %d", p, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read webdav response body: %v", err)
+	}
+	return string(body), nil
+}
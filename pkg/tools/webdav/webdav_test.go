@@ -0,0 +1,163 @@
+package webdav
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	xwebdav "golang.org/x/net/webdav"
+)
+
+// aferoWebdavFS adapts an afero.Fs to webdav.FileSystem, so these tests can
+// exercise the client against a real golang.org/x/net/webdav handler without
+// touching disk or running an external WebDAV server.
+type aferoWebdavFS struct {
+	fs afero.Fs
+}
+
+func (a aferoWebdavFS) Mkdir(_ context.Context, name string, perm os.FileMode) error {
+	return a.fs.Mkdir(name, perm)
+}
+
+func (a aferoWebdavFS) OpenFile(_ context.Context, name string, flag int, perm os.FileMode) (xwebdav.File, error) {
+	return a.fs.OpenFile(name, flag, perm)
+}
+
+func (a aferoWebdavFS) RemoveAll(_ context.Context, name string) error {
+	return a.fs.RemoveAll(name)
+}
+
+func (a aferoWebdavFS) Rename(_ context.Context, oldName, newName string) error {
+	return a.fs.Rename(oldName, newName)
+}
+
+func (a aferoWebdavFS) Stat(_ context.Context, name string) (os.FileInfo, error) {
+	return a.fs.Stat(name)
+}
+
+// newTestServer starts an in-process WebDAV server backed by an
+// afero.MemMapFs, for tests to exercise the client against.
+func newTestServer(t *testing.T) (*httptest.Server, afero.Fs) {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	handler := &xwebdav.Handler{
+		FileSystem: aferoWebdavFS{fs: fs},
+		LockSystem: xwebdav.NewMemLS(),
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv, fs
+}
+
+// generateTestCert builds a throwaway self-signed certificate, for tests
+// that only need a well-formed tls.Certificate to plumb through, not a
+// genuinely trusted one.
+func generateTestCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate test key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gendo-webdav-test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create test certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestWithClientCertConfiguresTransport(t *testing.T) {
+	cert := generateTestCert(t)
+	tool := New("https://example.invalid", WithClientCert(cert))
+
+	transport, ok := tool.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("httpClient.Transport = %T, want *http.Transport", tool.httpClient.Transport)
+	}
+	if transport.TLSClientConfig == nil || len(transport.TLSClientConfig.Certificates) != 1 {
+		t.Fatalf("TLSClientConfig.Certificates = %v, want exactly the configured cert", transport.TLSClientConfig)
+	}
+}
+
+func TestToolPutAndGet(t *testing.T) {
+	srv, _ := newTestServer(t)
+	tool := New(srv.URL)
+
+	if _, err := tool.Process(`{"op":"put","path":"/file.txt","content":"hello"}`); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	got, err := tool.Process(`{"op":"get","path":"/file.txt"}`)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if got != "hello" {
+		t.Errorf("get = %q, want %q", got, "hello")
+	}
+}
+
+func TestToolLegacyPlainPathGet(t *testing.T) {
+	srv, fs := newTestServer(t)
+	if err := afero.WriteFile(fs, "/legacy.txt", []byte("legacy"), 0644); err != nil {
+		t.Fatalf("failed to seed fs: %v", err)
+	}
+
+	tool := New(srv.URL)
+	got, err := tool.Process("/legacy.txt")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if got != "legacy" {
+		t.Errorf("get = %q, want %q", got, "legacy")
+	}
+}
+
+func TestToolMkcolAndDelete(t *testing.T) {
+	srv, _ := newTestServer(t)
+	tool := New(srv.URL)
+
+	if _, err := tool.Process(`{"op":"mkcol","path":"/dir"}`); err != nil {
+		t.Fatalf("mkcol failed: %v", err)
+	}
+	if _, err := tool.Process(`{"op":"put","path":"/dir/file.txt","content":"x"}`); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if _, err := tool.Process(`{"op":"delete","path":"/dir/file.txt"}`); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := tool.Process(`{"op":"get","path":"/dir/file.txt"}`); err == nil {
+		t.Error("get after delete expected error, got none")
+	}
+}
+
+func TestToolPropfind(t *testing.T) {
+	srv, fs := newTestServer(t)
+	if err := afero.WriteFile(fs, "/file.txt", []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to seed fs: %v", err)
+	}
+
+	tool := New(srv.URL)
+	got, err := tool.Process(`{"op":"stat","path":"/file.txt"}`)
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if got == "" {
+		t.Error("stat returned an empty body")
+	}
+}
@@ -1,52 +1,183 @@
 package write
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
 
 	"gendo/pkg/log"
+	"gendo/pkg/tools"
+	"gendo/pkg/tools/sandbox"
 )
 
+// maxWriteBytes bounds the size of a single write, so a Gendo script can't
+// exhaust disk space with one oversized tool call.
+const maxWriteBytes = 10 * 1024 * 1024 // 10 MiB
+
+// maxFiles bounds how many distinct files a single WriteTool instance will
+// create over its lifetime, so a runaway script can't flood the base
+// directory with new files.
+const maxFiles = 1000
+
+// writeRequest is the structured JSON form of a write tool call.
+type writeRequest struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
 // WriteTool implements the tools.Tool interface for file writing
 type WriteTool struct {
-	basePath string
+	fs     afero.Fs
+	logger *log.Logger
+
+	mu           sync.Mutex
+	writtenFiles map[string]struct{}
 }
 
-// NewWriteTool creates a new file writing tool
-func NewWriteTool(basePath string) *WriteTool {
-	log.Debug("Creating new write tool with base path: %q", basePath)
+// NewWriteTool creates a new file writing tool backed by the real OS
+// filesystem. It is a thin wrapper over NewWriteToolFS for the common case;
+// use NewWriteToolFS directly to write into an afero.Fs such as
+// afero.NewMemMapFs() (tests) or afero.NewCacheOnReadFs(...) (a read cache
+// in front of a slower backing store).
+func NewWriteTool(basePath string, logger *log.Logger) *WriteTool {
+	return NewWriteToolFS(afero.NewOsFs(), basePath, logger)
+}
+
+// NewWriteToolFS creates a new file writing tool backed by fs, confined to
+// basePath via a sandbox.BasePathFs, and logging through logger. An empty
+// basePath disables confinement, writing relative to fs's own root. A nil
+// logger falls back to log.Default().
+func NewWriteToolFS(fs afero.Fs, basePath string, logger *log.Logger) *WriteTool {
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Debug("Creating new write tool with base path: %q", basePath)
 	return &WriteTool{
-		basePath: basePath,
+		fs:           sandbox.NewBasePathFs(fs, basePath),
+		logger:       logger,
+		writtenFiles: make(map[string]struct{}),
 	}
 }
 
-// Process implements the tools.Tool interface for WriteTool
+// Process implements the tools.Tool interface for WriteTool. input may be a
+// JSON object {"path": "...", "content": "..."}, or, for backward
+// compatibility with scripts written before structured input was supported,
+// a "path content" pair separated by the first space.
 func (t *WriteTool) Process(input string) (string, error) {
-	log.Debug("Processing write input: %q", input)
+	t.logger.Debug("Processing write input: %q", input)
 
-	// Split input into file path and content
-	parts := strings.SplitN(input, " ", 2)
-	if len(parts) != 2 {
-		log.Debug("Invalid input format")
-		return "", fmt.Errorf("invalid input format: expected 'path content'")
+	path, content, err := parseWriteInput(input)
+	if err != nil {
+		return "", err
 	}
 
-	filePath := parts[0]
-	content := parts[1]
+	if len(content) > maxWriteBytes {
+		return "", fmt.Errorf("content exceeds the %d byte write limit", maxWriteBytes)
+	}
 
-	if t.basePath != "" {
-		filePath = filepath.Join(t.basePath, filePath)
-		log.Debug("Using full file path: %q", filePath)
+	if err := t.reserveFileQuota(path); err != nil {
+		return "", err
 	}
 
-	err := os.WriteFile(filePath, []byte(content), 0644)
-	if err != nil {
-		log.Debug("Failed to write to file %q: %v", filePath, err)
+	if err := afero.WriteFile(t.fs, path, []byte(content), 0644); err != nil {
+		t.logger.Debug("Failed to write to file %q: %v", path, err)
 		return "", fmt.Errorf("failed to write file: %v", err)
 	}
 
-	log.Debug("Successfully wrote %d bytes to %q", len(content), filePath)
-	return fmt.Sprintf("Successfully wrote to %s", filePath), nil
+	t.logger.Debug("Successfully wrote %d bytes to %q", len(content), path)
+	return fmt.Sprintf("Successfully wrote to %s", path), nil
+}
+
+// writeStreamChunkBytes bounds how much of content ProcessStream writes
+// between progress Tokens, so a "stream " node can observe and relay a
+// large write's progress instead of only seeing a single result at the end.
+const writeStreamChunkBytes = 64 * 1024 // 64 KiB
+
+// ProcessStream implements tools.StreamingTool. It performs the same
+// validation and quota bookkeeping as Process, then writes content in
+// writeStreamChunkBytes-sized chunks, emitting a progress Token after each
+// one, and a final Token carrying Process's usual result message.
+func (t *WriteTool) ProcessStream(input string) (<-chan tools.Token, error) {
+	t.logger.Debug("Processing write input (streaming): %q", input)
+
+	path, content, err := parseWriteInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) > maxWriteBytes {
+		return nil, fmt.Errorf("content exceeds the %d byte write limit", maxWriteBytes)
+	}
+
+	if err := t.reserveFileQuota(path); err != nil {
+		return nil, err
+	}
+
+	file, err := t.fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		t.logger.Debug("Failed to open file %q for streaming write: %v", path, err)
+		return nil, fmt.Errorf("failed to write file: %v", err)
+	}
+
+	ch := make(chan tools.Token)
+	go func() {
+		defer close(ch)
+		defer file.Close()
+
+		for written := 0; written < len(content); {
+			end := written + writeStreamChunkBytes
+			if end > len(content) {
+				end = len(content)
+			}
+			n, err := file.Write([]byte(content[written:end]))
+			written += n
+			if err != nil {
+				t.logger.Debug("Failed to write to file %q: %v", path, err)
+				ch <- tools.Token{Err: fmt.Errorf("failed to write file: %v", err)}
+				return
+			}
+			ch <- tools.Token{Text: fmt.Sprintf("wrote %d/%d bytes to %s", written, len(content), path)}
+		}
+
+		t.logger.Debug("Successfully wrote %d bytes to %q", len(content), path)
+		ch <- tools.Token{Text: fmt.Sprintf("Successfully wrote to %s", path), Done: true}
+	}()
+
+	return ch, nil
+}
+
+// reserveFileQuota records path as written by this tool instance, rejecting
+// the write if it would be a new file beyond maxFiles.
+func (t *WriteTool) reserveFileQuota(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, alreadyWritten := t.writtenFiles[path]; alreadyWritten {
+		return nil
+	}
+	if len(t.writtenFiles) >= maxFiles {
+		return fmt.Errorf("write tool has reached its limit of %d files", maxFiles)
+	}
+	t.writtenFiles[path] = struct{}{}
+	return nil
+}
+
+// parseWriteInput accepts either a JSON {"path": "...", "content": "..."}
+// object or a legacy "path content" pair separated by the first space.
+func parseWriteInput(input string) (path, content string, err error) {
+	var req writeRequest
+	if jsonErr := json.Unmarshal([]byte(input), &req); jsonErr == nil && req.Path != "" {
+		return req.Path, req.Content, nil
+	}
+
+	parts := strings.SplitN(input, " ", 2)
+	if len(parts) != 2 {
+		log.Debug("Invalid input format")
+		return "", "", fmt.Errorf("invalid input format: expected 'path content'")
+	}
+	return parts[0], parts[1], nil
 }
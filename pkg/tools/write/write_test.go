@@ -10,6 +10,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/spf13/afero"
 )
 
 func TestWriteTool(t *testing.T) {
@@ -56,7 +58,7 @@ func TestWriteTool(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tool := NewWriteTool(tt.basePath)
+			tool := NewWriteTool(tt.basePath, nil)
 			got, err := tool.Process(tt.input)
 
 			if tt.wantErr {
@@ -79,6 +81,119 @@ func TestWriteTool(t *testing.T) {
 	}
 }
 
+func TestWriteToolJSONInput(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gendo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tool := NewWriteTool(tmpDir, nil)
+	if _, err := tool.Process(`{"path":"test.txt","content":"json content"}`); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "test.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+	if string(content) != "json content" {
+		t.Errorf("Written content = %q, want %q", string(content), "json content")
+	}
+}
+
+func TestWriteToolPathEscape(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gendo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tool := NewWriteTool(tmpDir, nil)
+	if _, err := tool.Process("../escape.txt content"); err == nil {
+		t.Error("Process() expected error for path escaping base, got none")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(tmpDir), "escape.txt")); !os.IsNotExist(err) {
+		t.Error("escape.txt should not have been written outside the base directory")
+	}
+}
+
+func TestWriteToolFileQuota(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gendo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tool := NewWriteTool(tmpDir, nil)
+	for i := 0; i < maxFiles; i++ {
+		input := fmt.Sprintf(`{"path":"file-%d.txt","content":"x"}`, i)
+		if _, err := tool.Process(input); err != nil {
+			t.Fatalf("Process() unexpected error on file %d: %v", i, err)
+		}
+	}
+
+	if _, err := tool.Process(`{"path":"one-too-many.txt","content":"x"}`); err == nil {
+		t.Error("Process() expected quota error after reaching maxFiles, got none")
+	}
+}
+
+func TestWriteToolFS(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	tool := NewWriteToolFS(fs, "/data", nil)
+	if _, err := tool.Process("test.txt mem content"); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+
+	content, err := afero.ReadFile(fs, "/data/test.txt")
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+	if string(content) != "mem content" {
+		t.Errorf("Written content = %q, want %q", string(content), "mem content")
+	}
+}
+
+func TestWriteToolProcessStream(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	tool := NewWriteToolFS(fs, "/data", nil)
+
+	content := strings.Repeat("x", writeStreamChunkBytes*2+100)
+	ch, err := tool.ProcessStream(fmt.Sprintf("test.txt %s", content))
+	if err != nil {
+		t.Fatalf("ProcessStream() unexpected error: %v", err)
+	}
+
+	var progressTokens int
+	var final string
+	for token := range ch {
+		if token.Err != nil {
+			t.Fatalf("ProcessStream() token error: %v", token.Err)
+		}
+		if token.Done {
+			final = token.Text
+			continue
+		}
+		progressTokens++
+	}
+
+	if progressTokens != 3 {
+		t.Errorf("progress tokens = %d, want 3", progressTokens)
+	}
+	if !strings.HasPrefix(final, "Successfully wrote to") {
+		t.Errorf("final token = %q, want prefix %q", final, "Successfully wrote to")
+	}
+
+	written, err := afero.ReadFile(fs, "/data/test.txt")
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+	if string(written) != content {
+		t.Errorf("Written content length = %d, want %d", len(written), len(content))
+	}
+}
+
 func BenchmarkWriteTool(b *testing.B) {
 	// Create a temporary directory for benchmark files
 	tmpDir, err := os.MkdirTemp("", "gendo-bench-*")
@@ -94,7 +209,7 @@ func BenchmarkWriteTool(b *testing.B) {
 		"large":  1000000, // 1MB
 	}
 
-	tool := NewWriteTool(tmpDir)
+	tool := NewWriteTool(tmpDir, nil)
 
 	for name, size := range sizes {
 		content := strings.Repeat("x", size)
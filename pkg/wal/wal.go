@@ -0,0 +1,478 @@
+// Package wal provides a durable write-ahead log for Gendo pipeline runs,
+// recording each node invocation's input and, once known, its output or
+// error, so a crashed or interrupted run can resume without re-running
+// already-completed steps — important when a pipeline includes LLM calls
+// that are slow or billed per call. Records are appended to rotating
+// segment files under a directory, batching fsyncs on a background
+// goroutine instead of syncing on every Append.
+package wal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gendo/pkg/log"
+)
+
+// Phase values a Record can carry. A node invocation produces a PhaseStart
+// Record before it runs and a PhaseDone or PhaseError Record once it
+// returns. A PhaseCheckpoint Record instead marks an InputLineID whose
+// final output has been durably written to stdout, letting Compact reclaim
+// segments no resume will ever need again.
+const (
+	PhaseStart      = "start"
+	PhaseDone       = "done"
+	PhaseError      = "error"
+	PhaseCheckpoint = "checkpoint"
+)
+
+// Record is a single entry in the write-ahead log.
+type Record struct {
+	InputLineID int    `json:"input_line_id"`
+	NodeID      int    `json:"node_id,omitempty"`
+	Phase       string `json:"phase"`
+	Input       string `json:"input,omitempty"`
+	Output      string `json:"output,omitempty"`
+	Err         string `json:"err,omitempty"`
+}
+
+// maxSegmentBytes bounds how large a single segment file grows before
+// Manager rotates to a new one, giving Compact granularity to reclaim disk
+// space without waiting for the whole WAL directory to become stale.
+const maxSegmentBytes = 8 * 1024 * 1024 // 8 MiB
+
+// flushInterval bounds how long an Append can sit unflushed in the
+// background batching goroutine before it is fsynced, trading a small
+// durability window for not paying a full fsync on every node invocation.
+const flushInterval = 50 * time.Millisecond
+
+// flushBatchSize fsyncs early, before flushInterval elapses, once this many
+// Appends have queued up since the last flush.
+const flushBatchSize = 32
+
+const manifestName = "manifest.json"
+
+// manifest is the small JSON file recording which segment is currently being
+// appended to, so Open and Compact know which segments are sealed (and
+// therefore safe to delete once every InputLineID they mention is
+// checkpointed).
+type manifest struct {
+	Active int `json:"active"`
+}
+
+// Manager is a durable write-ahead log for one Gendo pipeline run, rooted at
+// a directory on disk. A nil logger falls back to log.Default().
+type Manager struct {
+	dir    string
+	logger *log.Logger
+
+	file    *os.File
+	segment int
+	written int64
+
+	appendCh  chan appendRequest
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+type appendRequest struct {
+	rec  Record
+	errc chan error
+}
+
+// Open creates dir if needed and opens (or resumes appending to) the write-
+// ahead log under it, starting the background batching goroutine. Callers
+// should Scan dir for replay before calling Open, since Open itself does not
+// read existing records, only positions the manager to append after them.
+func Open(dir string, logger *log.Logger) (*Manager, error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create WAL directory %q: %v", dir, err)
+	}
+
+	mf, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(segmentPath(dir, mf.Active), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL segment: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat WAL segment: %v", err)
+	}
+
+	m := &Manager{
+		dir:      dir,
+		logger:   logger,
+		file:     file,
+		segment:  mf.Active,
+		written:  info.Size(),
+		appendCh: make(chan appendRequest),
+		stopped:  make(chan struct{}),
+	}
+	go m.loop()
+
+	logger.Debug("Opened WAL at %q, active segment %d", dir, m.segment)
+	return m, nil
+}
+
+// Append durably records rec, fsyncing it (possibly alongside other Appends
+// queued in the same batch, see flushInterval/flushBatchSize) before
+// returning.
+func (m *Manager) Append(rec Record) error {
+	errc := make(chan error, 1)
+	select {
+	case m.appendCh <- appendRequest{rec: rec, errc: errc}:
+	case <-m.stopped:
+		return fmt.Errorf("wal: manager closed")
+	}
+	return <-errc
+}
+
+// Checkpoint records that inputLineID's final output has been durably
+// written to stdout, letting Compact reclaim WAL segments that only
+// describe it and earlier lines.
+func (m *Manager) Checkpoint(inputLineID int) error {
+	return m.Append(Record{InputLineID: inputLineID, Phase: PhaseCheckpoint})
+}
+
+// Close flushes any pending Appends and stops the background goroutine.
+func (m *Manager) Close() error {
+	m.closeOnce.Do(func() { close(m.stopped) })
+	return m.file.Close()
+}
+
+func (m *Manager) loop() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var pending []appendRequest
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		err := m.file.Sync()
+		if err != nil {
+			m.logger.Error("WAL fsync failed: %v", err)
+		}
+		for _, p := range pending {
+			p.errc <- err
+		}
+		pending = pending[:0]
+	}
+
+	for {
+		select {
+		case req := <-m.appendCh:
+			if err := m.writeRecord(req.rec); err != nil {
+				req.errc <- err
+				continue
+			}
+			pending = append(pending, req)
+			if len(pending) >= flushBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-m.stopped:
+			flush()
+			return
+		}
+	}
+}
+
+// writeRecord serializes rec as one JSON line, appends it to the active
+// segment, and rotates to a new segment if that pushes it past
+// maxSegmentBytes. It is only ever called from the loop goroutine.
+func (m *Manager) writeRecord(rec Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode WAL record: %v", err)
+	}
+	line = append(line, '\n')
+
+	n, err := m.file.Write(line)
+	m.written += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to append WAL record: %v", err)
+	}
+
+	if m.written >= maxSegmentBytes {
+		if err := m.rotate(); err != nil {
+			m.logger.Error("WAL rotation failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// rotate seals the active segment and starts a new one, persisting the
+// change to the manifest so Open resumes from the right place after a
+// restart.
+func (m *Manager) rotate() error {
+	if err := m.file.Sync(); err != nil {
+		return err
+	}
+	if err := m.file.Close(); err != nil {
+		return err
+	}
+
+	m.segment++
+	if err := writeManifest(m.dir, manifest{Active: m.segment}); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(segmentPath(m.dir, m.segment), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	m.file = file
+	m.written = 0
+	m.logger.Debug("Rotated WAL to segment %d", m.segment)
+	return nil
+}
+
+// Compact deletes sealed (non-active) segments whose every Record concerns
+// an InputLineID at or below checkpointed, the highest InputLineID a
+// PhaseCheckpoint Record has confirmed is durably committed to stdout. A
+// resume never needs to look earlier than that line, so the segments
+// describing only it and earlier lines are safe to remove.
+func (m *Manager) Compact(checkpointed int) error {
+	segments, err := sealedSegments(m.dir, m.segment)
+	if err != nil {
+		return err
+	}
+
+	for _, seg := range segments {
+		maxLine, err := maxInputLineID(segmentPath(m.dir, seg))
+		if err != nil {
+			m.logger.Error("Failed to inspect WAL segment %d for compaction: %v", seg, err)
+			continue
+		}
+		if maxLine > checkpointed {
+			continue
+		}
+		if err := os.Remove(segmentPath(m.dir, seg)); err != nil {
+			m.logger.Error("Failed to remove compacted WAL segment %d: %v", seg, err)
+			continue
+		}
+		m.logger.Debug("Compacted WAL segment %d (all lines <= %d)", seg, checkpointed)
+	}
+	return nil
+}
+
+// maxInputLineID returns the highest InputLineID mentioned anywhere in the
+// segment file at path.
+func maxInputLineID(path string) (int, error) {
+	records, err := readSegment(path)
+	if err != nil {
+		return 0, err
+	}
+	max := -1
+	for _, r := range records {
+		if r.InputLineID > max {
+			max = r.InputLineID
+		}
+	}
+	return max, nil
+}
+
+// sealedSegments lists the segment numbers under dir, excluding active.
+func sealedSegments(dir string, active int) ([]int, error) {
+	all, err := segmentNumbers(dir)
+	if err != nil {
+		return nil, err
+	}
+	var sealed []int
+	for _, n := range all {
+		if n != active {
+			sealed = append(sealed, n)
+		}
+	}
+	return sealed, nil
+}
+
+// segmentNumbers lists the numbers of every segment-N.log file under dir, in
+// ascending order.
+func segmentNumbers(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list WAL directory %q: %v", dir, err)
+	}
+
+	var nums []int
+	for _, entry := range entries {
+		n, ok := parseSegmentName(entry.Name())
+		if ok {
+			nums = append(nums, n)
+		}
+	}
+	sort.Ints(nums)
+	return nums, nil
+}
+
+func parseSegmentName(name string) (int, bool) {
+	if !strings.HasPrefix(name, "segment-") || !strings.HasSuffix(name, ".log") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "segment-"), ".log"))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func segmentPath(dir string, n int) string {
+	return filepath.Join(dir, fmt.Sprintf("segment-%d.log", n))
+}
+
+// readManifest reads dir's manifest, defaulting to segment 1 if none exists
+// yet (a fresh WAL directory).
+func readManifest(dir string) (manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest{Active: 1}, nil
+		}
+		return manifest{}, fmt.Errorf("failed to read WAL manifest: %v", err)
+	}
+
+	var mf manifest
+	if err := json.Unmarshal(data, &mf); err != nil {
+		return manifest{}, fmt.Errorf("failed to parse WAL manifest: %v", err)
+	}
+	if mf.Active == 0 {
+		mf.Active = 1
+	}
+	return mf, nil
+}
+
+// writeManifest persists mf to dir, via a temp file and rename so a crash
+// mid-write can never leave a half-written manifest behind.
+func writeManifest(dir string, mf manifest) error {
+	data, err := json.Marshal(mf)
+	if err != nil {
+		return fmt.Errorf("failed to encode WAL manifest: %v", err)
+	}
+
+	path := filepath.Join(dir, manifestName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write WAL manifest: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to commit WAL manifest: %v", err)
+	}
+	return nil
+}
+
+// Scan reads every Record from every segment under dir, in append order, for
+// replay (see Replay) or inspection (see cmd/gendo's walinspect subcommand).
+// A missing dir is not an error; it reports no records, as a fresh pipeline
+// with no prior run would.
+func Scan(dir string) ([]Record, error) {
+	nums, err := segmentNumbers(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, n := range nums {
+		segRecords, err := readSegment(segmentPath(dir, n))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, segRecords...)
+	}
+	return records, nil
+}
+
+// readSegment decodes every complete JSON line in the segment file at path.
+// A trailing partial line (the tell-tale sign of a crash mid-append) is
+// ignored rather than treated as an error, since everything before it is
+// still valid and replayable.
+func readSegment(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open WAL segment %q: %v", path, err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			break
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// CompletedNode holds a node's already-recorded output or error for a
+// specific InputLineID, as found by Replay.
+type CompletedNode struct {
+	Output string
+	Err    string
+}
+
+// Replay scans records (as returned by Scan) and returns the InputLineID to
+// resume processing from — one past the highest checkpointed line, or 0 for
+// a clean start — along with the already-completed nodes recorded for that
+// line, keyed by NodeID, so a resumed run can reuse their output instead of
+// re-invoking a tool or LLM that already ran.
+func Replay(records []Record) (resumeFrom int, completed map[int]CompletedNode) {
+	checkpoint := -1
+	byLine := make(map[int]map[int]CompletedNode)
+
+	for _, r := range records {
+		switch r.Phase {
+		case PhaseCheckpoint:
+			if r.InputLineID > checkpoint {
+				checkpoint = r.InputLineID
+			}
+		case PhaseDone:
+			setCompleted(byLine, r.InputLineID, r.NodeID, CompletedNode{Output: r.Output})
+		case PhaseError:
+			setCompleted(byLine, r.InputLineID, r.NodeID, CompletedNode{Err: r.Err})
+		}
+	}
+
+	resumeFrom = checkpoint + 1
+	return resumeFrom, byLine[resumeFrom]
+}
+
+func setCompleted(byLine map[int]map[int]CompletedNode, lineID, nodeID int, cn CompletedNode) {
+	m := byLine[lineID]
+	if m == nil {
+		m = make(map[int]CompletedNode)
+		byLine[lineID] = m
+	}
+	m[nodeID] = cn
+}
@@ -0,0 +1,159 @@
+// Package wal contains test cases for the write-ahead log, covering durable
+// appends, crash-replay via Scan/Replay, and segment compaction.
+package wal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManagerAppendAndScan(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := Open(dir, nil)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+
+	records := []Record{
+		{InputLineID: 0, NodeID: 3, Phase: PhaseStart, Input: "hello"},
+		{InputLineID: 0, NodeID: 3, Phase: PhaseDone, Output: "HELLO"},
+		{InputLineID: 0, Phase: PhaseCheckpoint},
+	}
+	for _, rec := range records {
+		if err := m.Append(rec); err != nil {
+			t.Fatalf("Append(%+v) unexpected error: %v", rec, err)
+		}
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+
+	got, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	if len(got) != len(records) {
+		t.Fatalf("Scan() returned %d records, want %d", len(got), len(records))
+	}
+	for i, rec := range records {
+		if got[i] != rec {
+			t.Errorf("Scan()[%d] = %+v, want %+v", i, got[i], rec)
+		}
+	}
+}
+
+func TestScanMissingDir(t *testing.T) {
+	records, err := Scan(filepath.Join(t.TempDir(), "does-not-exist"))
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("Scan() = %v, want no records for a missing directory", records)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name            string
+		records         []Record
+		wantResumeFrom  int
+		wantCompletedID int
+		wantHasNode     bool
+	}{
+		{
+			name:           "no records resumes from the start",
+			records:        nil,
+			wantResumeFrom: 0,
+		},
+		{
+			name: "completed line is skipped entirely",
+			records: []Record{
+				{InputLineID: 0, NodeID: 3, Phase: PhaseDone, Output: "ok"},
+				{InputLineID: 0, Phase: PhaseCheckpoint},
+			},
+			wantResumeFrom: 1,
+		},
+		{
+			name: "in-flight line resumes with its completed node reusable",
+			records: []Record{
+				{InputLineID: 0, Phase: PhaseCheckpoint},
+				{InputLineID: 1, NodeID: 3, Phase: PhaseStart, Input: "x"},
+				{InputLineID: 1, NodeID: 3, Phase: PhaseDone, Output: "X"},
+			},
+			wantResumeFrom:  1,
+			wantCompletedID: 3,
+			wantHasNode:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resumeFrom, completed := Replay(tt.records)
+			if resumeFrom != tt.wantResumeFrom {
+				t.Errorf("Replay() resumeFrom = %d, want %d", resumeFrom, tt.wantResumeFrom)
+			}
+			cn, ok := completed[tt.wantCompletedID]
+			if ok != tt.wantHasNode {
+				t.Errorf("Replay() completed[%d] present = %v, want %v", tt.wantCompletedID, ok, tt.wantHasNode)
+			}
+			if tt.wantHasNode && cn.Output != "X" {
+				t.Errorf("Replay() completed[%d].Output = %q, want %q", tt.wantCompletedID, cn.Output, "X")
+			}
+		})
+	}
+}
+
+func TestCompactRemovesFullyCheckpointedSegments(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := writeManifest(dir, manifest{Active: 2}); err != nil {
+		t.Fatalf("writeManifest() unexpected error: %v", err)
+	}
+	writeSegment(t, dir, 1, []Record{
+		{InputLineID: 0, NodeID: 3, Phase: PhaseDone, Output: "a"},
+		{InputLineID: 0, Phase: PhaseCheckpoint},
+	})
+	writeSegment(t, dir, 2, []Record{
+		{InputLineID: 1, NodeID: 3, Phase: PhaseDone, Output: "b"},
+	})
+
+	m, err := Open(dir, nil)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	defer m.Close()
+
+	if err := m.Compact(0); err != nil {
+		t.Fatalf("Compact() unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(segmentPath(dir, 1)); !os.IsNotExist(err) {
+		t.Errorf("segment 1 should have been compacted away, stat err = %v", err)
+	}
+	if _, err := os.Stat(segmentPath(dir, 2)); err != nil {
+		t.Errorf("active segment 2 should survive compaction, stat err = %v", err)
+	}
+}
+
+func writeSegment(t *testing.T, dir string, n int, records []Record) {
+	t.Helper()
+	f, err := os.OpenFile(segmentPath(dir, n), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to create segment %d: %v", n, err)
+	}
+	defer f.Close()
+
+	for _, rec := range records {
+		data, err := json.Marshal(rec)
+		if err != nil {
+			t.Fatalf("failed to encode record: %v", err)
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			t.Fatalf("failed to write record: %v", err)
+		}
+	}
+}